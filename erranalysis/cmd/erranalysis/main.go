@@ -0,0 +1,11 @@
+// Package main provides a command that runs the [erranalysis.Analyzer].
+package main
+
+import (
+	"github.com/pierrre/errors/erranalysis"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(erranalysis.Analyzer)
+}
@@ -0,0 +1,492 @@
+// Package erranalysis provides a static analyzer that checks the usage of the errors library.
+//
+// It reports:
+//   - "globalnew": [errors.New] or [errors.Newf] called in a package-level variable initializer (use [errbase.New] or [errbase.Newf] instead)
+//   - "errorf": [fmt.Errorf] called with the %w verb in a package importing the errors library (use [errors.Wrap] or [errors.Wrapf] instead)
+//   - "wrapfw": [errors.Wrapf] called with the %w verb, which is not supported
+//   - "newfconst": [errors.Newf] called with a constant message (use [errors.New] instead)
+//   - "key": [errtag] or [errval] called with a key that is not a constant
+//   - "returnwrap": an error returned by a function of another package is returned without being wrapped
+//
+// Each check can be disabled with a flag of the same name.
+// A suggested fix is provided where possible.
+//
+// [errors.New]: https://pkg.go.dev/github.com/pierrre/errors#New
+// [errors.Newf]: https://pkg.go.dev/github.com/pierrre/errors#Newf
+// [errors.Wrap]: https://pkg.go.dev/github.com/pierrre/errors#Wrap
+// [errors.Wrapf]: https://pkg.go.dev/github.com/pierrre/errors#Wrapf
+// [errbase.New]: https://pkg.go.dev/github.com/pierrre/errors/errbase#New
+// [errbase.Newf]: https://pkg.go.dev/github.com/pierrre/errors/errbase#Newf
+// [errtag]: https://pkg.go.dev/github.com/pierrre/errors/errtag
+// [errval]: https://pkg.go.dev/github.com/pierrre/errors/errval
+package erranalysis
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pierrre/errors/internal/fmtverb"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const (
+	modulePath  = "github.com/pierrre/errors"
+	errbasePath = modulePath + "/errbase"
+	errmsgPath  = modulePath + "/errmsg"
+	errtagPath  = modulePath + "/errtag"
+	errvalPath  = modulePath + "/errval"
+)
+
+// Analyzer is the [analysis.Analyzer] that checks the usage of the errors library.
+var Analyzer = &analysis.Analyzer{
+	Name:     "erranalysis",
+	Doc:      "check the usage of the github.com/pierrre/errors library",
+	URL:      "https://pkg.go.dev/github.com/pierrre/errors/erranalysis",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var (
+	checkGlobalNew  = true
+	checkErrorf     = true
+	checkWrapfW     = true
+	checkNewfConst  = true
+	checkKey        = true
+	checkReturnWrap = true
+)
+
+func init() {
+	Analyzer.Flags.BoolVar(&checkGlobalNew, "globalnew", checkGlobalNew, "report errors.New/Newf called in package-level variable initializers")
+	Analyzer.Flags.BoolVar(&checkErrorf, "errorf", checkErrorf, "report fmt.Errorf called with %w")
+	Analyzer.Flags.BoolVar(&checkWrapfW, "wrapfw", checkWrapfW, "report errors.Wrapf called with %w")
+	Analyzer.Flags.BoolVar(&checkNewfConst, "newfconst", checkNewfConst, "report errors.Newf called with a constant message")
+	Analyzer.Flags.BoolVar(&checkKey, "key", checkKey, "report errtag/errval keys that are not constants")
+	Analyzer.Flags.BoolVar(&checkReturnWrap, "returnwrap", checkReturnWrap, "report errors returned from other packages without wrapping")
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector) //nolint:forcetypeassert // The type is guaranteed by the inspect analyzer.
+	usesModule := importsModule(pass.Pkg)
+	if checkGlobalNew {
+		runGlobalNew(pass)
+	}
+	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr) //nolint:forcetypeassert // The type is guaranteed by the filter.
+		runCall(pass, call, usesModule)
+	})
+	if checkReturnWrap && usesModule {
+		insp.Preorder([]ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}, func(n ast.Node) {
+			runReturnWrap(pass, n)
+		})
+	}
+	return nil, nil //nolint:nilnil // The analyzer doesn't return a result.
+}
+
+func importsModule(pkg *types.Package) bool {
+	for _, imp := range pkg.Imports() {
+		if isModulePath(imp.Path()) {
+			return true
+		}
+	}
+	return false
+}
+
+func isModulePath(p string) bool {
+	return p == modulePath || strings.HasPrefix(p, modulePath+"/")
+}
+
+func runCall(pass *analysis.Pass, call *ast.CallExpr, usesModule bool) {
+	fn := typeutil.StaticCallee(pass.TypesInfo, call)
+	if fn == nil || fn.Pkg() == nil {
+		return
+	}
+	path, name := fn.Pkg().Path(), fn.Name()
+	switch {
+	case path == "fmt" && name == "Errorf":
+		if checkErrorf && usesModule {
+			runErrorf(pass, call)
+		}
+	case (path == modulePath || path == errmsgPath) && name == "Wrapf":
+		if checkWrapfW {
+			runWrapfW(pass, call)
+		}
+	case (path == modulePath || path == errbasePath) && name == "Newf":
+		if checkNewfConst {
+			runNewfConst(pass, call)
+		}
+	case path == errtagPath && strings.HasPrefix(name, "Wrap"), path == errvalPath && name == "Wrap":
+		if checkKey {
+			runKey(pass, call)
+		}
+	}
+}
+
+// runGlobalNew checks the package-level variable initializers.
+func runGlobalNew(pass *analysis.Pass) {
+	for _, file := range pass.Files {
+		var calls []*ast.CallExpr
+		for _, decl := range file.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec) //nolint:forcetypeassert // A var declaration only contains value specs.
+				for _, v := range vs.Values {
+					ast.Inspect(v, func(n ast.Node) bool {
+						switch n := n.(type) {
+						case *ast.FuncLit:
+							return false // The function body is not executed during the initialization.
+						case *ast.CallExpr:
+							if isGlobalNewCall(pass, n) {
+								calls = append(calls, n)
+							}
+						}
+						return true
+					})
+				}
+			}
+		}
+		if len(calls) > 0 {
+			runGlobalNewFile(pass, file, calls)
+		}
+	}
+}
+
+func isGlobalNewCall(pass *analysis.Pass, call *ast.CallExpr) bool {
+	fn := typeutil.StaticCallee(pass.TypesInfo, call)
+	return fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == modulePath && (fn.Name() == "New" || fn.Name() == "Newf")
+}
+
+// runGlobalNewFile reports the calls of a file.
+//
+// If the errors library import is only used by these calls, the fix replaces all of them and removes the import, so the fixed file compiles.
+// The fixes of the calls are identical in this case, so they can be applied together.
+func runGlobalNewFile(pass *analysis.Pass, file *ast.File, calls []*ast.CallExpr) {
+	name, importEdits := importName(file, errbasePath, "errbase")
+	removeEdit, remove := removeUnusedImport(pass, file, modulePath, len(calls))
+	for _, call := range calls {
+		fixCalls := []*ast.CallExpr{call}
+		edits := slices.Clone(importEdits)
+		if remove {
+			fixCalls = calls
+			edits = append(edits, removeEdit)
+		}
+		for _, c := range fixCalls {
+			edits = append(edits, analysis.TextEdit{
+				Pos:     c.Fun.Pos(),
+				End:     c.Fun.End(),
+				NewText: []byte(name + "." + calleeName(pass, c)),
+			})
+		}
+		fnName := calleeName(pass, call)
+		pass.Report(analysis.Diagnostic{
+			Pos:      call.Pos(),
+			End:      call.End(),
+			Category: "globalnew",
+			Message:  "errors." + fnName + " called in a package-level variable initializer, use errbase." + fnName + " instead",
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message:   "Replace with errbase." + fnName,
+				TextEdits: edits,
+			}},
+		})
+	}
+}
+
+func calleeName(pass *analysis.Pass, call *ast.CallExpr) string {
+	return typeutil.StaticCallee(pass.TypesInfo, call).Name()
+}
+
+// removeUnusedImport returns the edit that removes the import of a package from a file, if the file uses it exactly count times.
+func removeUnusedImport(pass *analysis.Pass, file *ast.File, path string, count int) (analysis.TextEdit, bool) {
+	for _, spec := range file.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || p != path {
+			continue
+		}
+		pkgName := pass.TypesInfo.PkgNameOf(spec)
+		if pkgName == nil {
+			return analysis.TextEdit{}, false
+		}
+		uses := 0
+		for id, obj := range pass.TypesInfo.Uses {
+			if obj == pkgName && file.FileStart <= id.Pos() && id.Pos() < file.FileEnd {
+				uses++
+			}
+		}
+		if uses != count {
+			return analysis.TextEdit{}, false
+		}
+		return removeImportEdit(pass, file, spec), true
+	}
+	return analysis.TextEdit{}, false
+}
+
+// removeImportEdit returns the edit that removes an import spec.
+//
+// The whole declaration is removed if it is not parenthesized, otherwise the line of the spec is removed.
+func removeImportEdit(pass *analysis.Pass, file *ast.File, spec *ast.ImportSpec) analysis.TextEdit {
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if ok && gd.Tok == token.IMPORT && !gd.Lparen.IsValid() && len(gd.Specs) == 1 && gd.Specs[0] == spec {
+			return analysis.TextEdit{
+				Pos: gd.Pos(),
+				End: gd.End(),
+			}
+		}
+	}
+	tf := pass.Fset.File(spec.Pos())
+	line := tf.Line(spec.Pos())
+	end := spec.End()
+	if line < tf.LineCount() {
+		end = tf.LineStart(line + 1)
+	}
+	return analysis.TextEdit{
+		Pos: tf.LineStart(line),
+		End: end,
+	}
+}
+
+func runErrorf(pass *analysis.Pass, call *ast.CallExpr) {
+	if len(call.Args) == 0 {
+		return
+	}
+	format, ok := constString(pass, call.Args[0])
+	if !ok || !fmtverb.Has(format, 'w') {
+		return
+	}
+	diag := analysis.Diagnostic{
+		Pos:      call.Pos(),
+		End:      call.End(),
+		Category: "errorf",
+		Message:  "fmt.Errorf called with %w, use errors.Wrap or errors.Wrapf instead",
+	}
+	fix, ok := errorfFix(pass, call, format)
+	if ok {
+		diag.SuggestedFixes = []analysis.SuggestedFix{fix}
+	}
+	pass.Report(diag)
+}
+
+// errorfFix returns a fix for fmt.Errorf("msg: %w", args..., err).
+//
+// It requires that the file imports the errors library, that the format is a literal with a single %w verb at the end, and that the error is the last argument.
+func errorfFix(pass *analysis.Pass, call *ast.CallExpr, format string) (analysis.SuggestedFix, bool) {
+	if _, ok := call.Args[0].(*ast.BasicLit); !ok || call.Ellipsis.IsValid() || len(call.Args) < 2 {
+		return analysis.SuggestedFix{}, false
+	}
+	msg, ok := fmtverb.CutWrapSuffix(format)
+	if !ok {
+		return analysis.SuggestedFix{}, false
+	}
+	args := call.Args[1 : len(call.Args)-1]
+	if len(args) == 0 {
+		// Wrap doesn't format the message, so escaped percent signs must be unescaped.
+		msg, ok = fmtverb.Unescape(msg)
+		if !ok {
+			return analysis.SuggestedFix{}, false
+		}
+	}
+	name, ok := fileImportName(fileOf(pass, call.Pos()), modulePath)
+	if !ok {
+		return analysis.SuggestedFix{}, false
+	}
+	errArg := call.Args[len(call.Args)-1]
+	wrapName := "Wrap"
+	if len(args) > 0 {
+		wrapName = "Wrapf"
+	}
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteString(".")
+	b.WriteString(wrapName)
+	b.WriteString("(")
+	b.WriteString(nodeText(pass, errArg))
+	b.WriteString(", ")
+	b.WriteString(strconv.Quote(msg))
+	for _, arg := range args {
+		b.WriteString(", ")
+		b.WriteString(nodeText(pass, arg))
+	}
+	b.WriteString(")")
+	return analysis.SuggestedFix{
+		Message: "Replace with " + name + "." + wrapName,
+		TextEdits: []analysis.TextEdit{{
+			Pos:     call.Pos(),
+			End:     call.End(),
+			NewText: []byte(b.String()),
+		}},
+	}, true
+}
+
+func runWrapfW(pass *analysis.Pass, call *ast.CallExpr) {
+	if len(call.Args) < 2 {
+		return
+	}
+	format, ok := constString(pass, call.Args[1])
+	if !ok || !fmtverb.Has(format, 'w') {
+		return
+	}
+	diag := analysis.Diagnostic{
+		Pos:      call.Pos(),
+		End:      call.End(),
+		Category: "wrapfw",
+		Message:  "Wrapf doesn't support the %w verb",
+	}
+	lit, ok := call.Args[1].(*ast.BasicLit)
+	if ok {
+		diag.SuggestedFixes = []analysis.SuggestedFix{{
+			Message: "Replace %w with %v",
+			TextEdits: []analysis.TextEdit{{
+				Pos:     lit.Pos(),
+				End:     lit.End(),
+				NewText: []byte(quote(replaceWrapVerbs(format), lit.Value)),
+			}},
+		}}
+	}
+	pass.Report(diag)
+}
+
+// replaceWrapVerbs replaces the %w verbs of the format with %v, and keeps their flags and argument indexes.
+func replaceWrapVerbs(format string) string {
+	b := []byte(format)
+	for v := range fmtverb.All(format) {
+		if v.Verb == 'w' {
+			b[v.End-1] = 'v'
+		}
+	}
+	return string(b)
+}
+
+// quote quotes s with the same kind of quote as the original literal.
+func quote(s string, original string) string {
+	if strings.HasPrefix(original, "`") && !strings.Contains(s, "`") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+func runNewfConst(pass *analysis.Pass, call *ast.CallExpr) {
+	if len(call.Args) != 1 {
+		return
+	}
+	format, ok := constString(pass, call.Args[0])
+	if !ok || strings.Contains(format, "%") {
+		return
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	pass.Report(analysis.Diagnostic{
+		Pos:      call.Pos(),
+		End:      call.End(),
+		Category: "newfconst",
+		Message:  "Newf called with a constant message, use New instead",
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: "Replace with New",
+			TextEdits: []analysis.TextEdit{{
+				Pos:     sel.Sel.Pos(),
+				End:     sel.Sel.End(),
+				NewText: []byte("New"),
+			}},
+		}},
+	})
+}
+
+func runKey(pass *analysis.Pass, call *ast.CallExpr) {
+	if len(call.Args) < 2 {
+		return
+	}
+	key := call.Args[1]
+	if pass.TypesInfo.Types[key].Value != nil {
+		return
+	}
+	pass.Report(analysis.Diagnostic{
+		Pos:      key.Pos(),
+		End:      key.End(),
+		Category: "key",
+		Message:  "the key is not a constant",
+	})
+}
+
+func constString(pass *analysis.Pass, expr ast.Expr) (string, bool) {
+	v := pass.TypesInfo.Types[expr].Value
+	if v == nil || v.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(v), true
+}
+
+func fileOf(pass *analysis.Pass, pos token.Pos) *ast.File {
+	for _, file := range pass.Files {
+		if file.FileStart <= pos && pos < file.FileEnd {
+			return file
+		}
+	}
+	return nil
+}
+
+// fileImportName returns the name used by a file to import a package.
+func fileImportName(file *ast.File, path string) (string, bool) {
+	if file == nil {
+		return "", false
+	}
+	for _, spec := range file.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || p != path {
+			continue
+		}
+		if spec.Name != nil {
+			if spec.Name.Name == "_" || spec.Name.Name == "." {
+				return "", false
+			}
+			return spec.Name.Name, true
+		}
+		return path[strings.LastIndex(path, "/")+1:], true
+	}
+	return "", false
+}
+
+// importName returns the name used by a file to import a package, and the edits required to add the import if it is missing.
+func importName(file *ast.File, path string, defaultName string) (string, []analysis.TextEdit) {
+	name, ok := fileImportName(file, path)
+	if ok {
+		return name, nil
+	}
+	var edit analysis.TextEdit
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if ok && gd.Tok == token.IMPORT && gd.Rparen.IsValid() {
+			edit = analysis.TextEdit{
+				Pos:     gd.Rparen,
+				End:     gd.Rparen,
+				NewText: []byte("\t" + strconv.Quote(path) + "\n"),
+			}
+			return defaultName, []analysis.TextEdit{edit}
+		}
+	}
+	edit = analysis.TextEdit{
+		Pos:     file.Name.End(),
+		End:     file.Name.End(),
+		NewText: []byte("\n\nimport " + strconv.Quote(path)),
+	}
+	return defaultName, []analysis.TextEdit{edit}
+}
+
+func nodeText(pass *analysis.Pass, n ast.Node) string {
+	tf := pass.Fset.File(n.Pos())
+	content, err := pass.ReadFile(tf.Name())
+	if err != nil {
+		return types.ExprString(n.(ast.Expr)) //nolint:forcetypeassert // Only expressions are passed.
+	}
+	return string(content[tf.Offset(n.Pos()):tf.Offset(n.End())])
+}
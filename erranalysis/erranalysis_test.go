@@ -0,0 +1,48 @@
+package erranalysis_test
+
+import (
+	"testing"
+
+	"github.com/pierrre/assert"
+	. "github.com/pierrre/errors/erranalysis"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func Test(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), Analyzer, "a", "b", "c", "d")
+}
+
+func collectDiagnostics(t *testing.T, pkg string) []analysis.Diagnostic {
+	t.Helper()
+	var diags []analysis.Diagnostic
+	for _, res := range analysistest.Run(t, analysistest.TestData(), Analyzer, pkg) {
+		diags = append(diags, res.Diagnostics...)
+	}
+	return diags
+}
+
+func TestSuggestedFixMessages(t *testing.T) {
+	var messages []string
+	for _, diag := range collectDiagnostics(t, "a") {
+		for _, fix := range diag.SuggestedFixes {
+			messages = append(messages, fix.Message)
+		}
+	}
+	assert.SliceContains(t, messages, "Replace with errors.Wrap")
+	assert.SliceContains(t, messages, "Replace with errors.Wrapf")
+}
+
+// TestGlobalNewRemoveImport checks the edits directly, because the test driver removes the unused imports itself.
+func TestGlobalNewRemoveImport(t *testing.T) {
+	diags := collectDiagnostics(t, "d")
+	assert.SliceLen(t, diags, 2)
+	for _, diag := range diags {
+		assert.SliceLen(t, diag.SuggestedFixes, 1)
+		removed := false
+		for _, edit := range diag.SuggestedFixes[0].TextEdits {
+			removed = removed || len(edit.NewText) == 0
+		}
+		assert.True(t, removed)
+	}
+}
@@ -0,0 +1,164 @@
+package erranalysis
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// assignment is an assignment of a variable.
+//
+// call is nil if the assigned value is not the result of a function call.
+type assignment struct {
+	pos  token.Pos
+	call *ast.CallExpr
+}
+
+// runReturnWrap checks the return statements of a function.
+//
+// Nested function literals are checked separately.
+func runReturnWrap(pass *analysis.Pass, fn ast.Node) {
+	var body *ast.BlockStmt
+	switch fn := fn.(type) {
+	case *ast.FuncDecl:
+		body = fn.Body
+	case *ast.FuncLit:
+		body = fn.Body
+	}
+	if body == nil {
+		return
+	}
+	assigns := make(map[*types.Var][]assignment)
+	var returns []*ast.ReturnStmt
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.AssignStmt:
+			recordAssignments(pass, assigns, n.Lhs, n.Rhs)
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(n.Names))
+			for i, name := range n.Names {
+				lhs[i] = name
+			}
+			recordAssignments(pass, assigns, lhs, n.Values)
+		case *ast.ReturnStmt:
+			returns = append(returns, n)
+		}
+		return true
+	})
+	for _, ret := range returns {
+		for _, res := range ret.Results {
+			checkReturn(pass, assigns, res)
+		}
+	}
+}
+
+func recordAssignments(pass *analysis.Pass, assigns map[*types.Var][]assignment, lhs, rhs []ast.Expr) {
+	for i, l := range lhs {
+		id, ok := l.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		v, ok := pass.TypesInfo.ObjectOf(id).(*types.Var)
+		if !ok {
+			continue
+		}
+		var r ast.Expr
+		switch len(rhs) {
+		case len(lhs):
+			r = rhs[i]
+		case 1:
+			r = rhs[0]
+		}
+		call, _ := ast.Unparen(r).(*ast.CallExpr)
+		assigns[v] = append(assigns[v], assignment{
+			pos:  l.Pos(),
+			call: call,
+		})
+	}
+}
+
+func checkReturn(pass *analysis.Pass, assigns map[*types.Var][]assignment, res ast.Expr) {
+	typ := pass.TypesInfo.TypeOf(res)
+	if tuple, ok := typ.(*types.Tuple); ok {
+		if tuple.Len() > 0 && isErrorType(tuple.At(tuple.Len()-1).Type()) {
+			call, _ := ast.Unparen(res).(*ast.CallExpr)
+			reportReturnWrap(pass, res, call, false)
+		}
+		return
+	}
+	if !isErrorType(typ) {
+		return
+	}
+	var call *ast.CallExpr
+	switch e := ast.Unparen(res).(type) {
+	case *ast.CallExpr:
+		call = e
+	case *ast.Ident:
+		v, ok := pass.TypesInfo.Uses[e].(*types.Var)
+		if ok {
+			call = lastAssignmentCall(assigns[v], res.Pos())
+		}
+	}
+	reportReturnWrap(pass, res, call, true)
+}
+
+// lastAssignmentCall returns the call of the last assignment before pos.
+func lastAssignmentCall(assigns []assignment, pos token.Pos) *ast.CallExpr {
+	var call *ast.CallExpr
+	for _, a := range assigns {
+		if a.pos >= pos {
+			break
+		}
+		call = a.call
+	}
+	return call
+}
+
+func reportReturnWrap(pass *analysis.Pass, res ast.Expr, call *ast.CallExpr, withFix bool) {
+	if call == nil {
+		return
+	}
+	fn := externalCallee(pass, call)
+	if fn == nil {
+		return
+	}
+	diag := analysis.Diagnostic{
+		Pos:      res.Pos(),
+		End:      res.End(),
+		Category: "returnwrap",
+		Message:  "error returned by " + fn.FullName() + " is not wrapped",
+	}
+	if withFix {
+		name, ok := fileImportName(fileOf(pass, res.Pos()), modulePath)
+		if ok {
+			diag.SuggestedFixes = []analysis.SuggestedFix{{
+				Message: "Wrap with errors.Wrap",
+				TextEdits: []analysis.TextEdit{{
+					Pos:     res.Pos(),
+					End:     res.End(),
+					NewText: []byte(name + ".Wrap(" + nodeText(pass, res) + ", " + strconv.Quote(fn.Name()) + ")"),
+				}},
+			}}
+		}
+	}
+	pass.Report(diag)
+}
+
+// externalCallee returns the function called by call, if it is declared in another package, which is not part of the errors library.
+func externalCallee(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg() == pass.Pkg || isModulePath(fn.Pkg().Path()) {
+		return nil
+	}
+	return fn
+}
+
+func isErrorType(typ types.Type) bool {
+	return typ != nil && types.Identical(typ, types.Universe.Lookup("error").Type())
+}
@@ -0,0 +1,98 @@
+package a
+
+import (
+	"fmt"
+
+	"ext"
+
+	"github.com/pierrre/errors"
+	"github.com/pierrre/errors/errbase"
+	"github.com/pierrre/errors/errtag"
+	"github.com/pierrre/errors/errval"
+)
+
+var errGlobal = errors.New("global") // want `errors.New called in a package-level variable initializer, use errbase.New instead`
+
+var errGlobalf = errors.Newf("global %d", 1) // want `errors.Newf called in a package-level variable initializer, use errbase.Newf instead`
+
+var errGlobalOK = errbase.New("global")
+
+var globalFunc = func() error {
+	return errors.New("ok")
+}
+
+const key = "key"
+
+func errorf(err error, x int) error {
+	_ = fmt.Errorf("msg: %w", err)          // want `fmt.Errorf called with %w, use errors.Wrap or errors.Wrapf instead`
+	_ = fmt.Errorf("msg %d: %w", x, err)    // want `fmt.Errorf called with %w, use errors.Wrap or errors.Wrapf instead`
+	_ = fmt.Errorf("%w: msg", err)          // want `fmt.Errorf called with %w, use errors.Wrap or errors.Wrapf instead`
+	_ = fmt.Errorf("100%% done: %w", err)   // want `fmt.Errorf called with %w, use errors.Wrap or errors.Wrapf instead`
+	_ = fmt.Errorf("%d%% done: %w", x, err) // want `fmt.Errorf called with %w, use errors.Wrap or errors.Wrapf instead`
+	_ = fmt.Errorf("%[1]d: %w", x, err)     // want `fmt.Errorf called with %w, use errors.Wrap or errors.Wrapf instead`
+	_ = fmt.Errorf("msg %d", x)
+	return nil
+}
+
+func wrapf(err error) error {
+	_ = errors.Wrapf(err, "msg %w", err)        // want `Wrapf doesn't support the %w verb`
+	_ = errors.Wrapf(err, "100%%w %w", err)     // want `Wrapf doesn't support the %w verb`
+	_ = errors.Wrapf(err, "msg %[1]w %+w", err) // want `Wrapf doesn't support the %w verb`
+	_ = errors.Wrapf(err, "msg %d", 1)
+	_ = errors.Wrapf(err, "100%%w")
+	return nil
+}
+
+func newf() error {
+	_ = errors.Newf("msg")  // want `Newf called with a constant message, use New instead`
+	_ = errbase.Newf("msg") // want `Newf called with a constant message, use New instead`
+	_ = errors.Newf("msg %d", 1)
+	_ = errors.Newf("100%%")
+	return nil
+}
+
+func keys(err error, k string) error {
+	_ = errtag.Wrap(err, key, "v")
+	_ = errtag.Wrap(err, "k", "v")
+	_ = errtag.Wrap(err, k, "v")   // want `the key is not a constant`
+	_ = errtag.WrapInt(err, k, 1)  // want `the key is not a constant`
+	_ = errval.Wrap(err, k+"v", 1) // want `the key is not a constant`
+	return nil
+}
+
+func returnWrap() error {
+	err := ext.F()
+	if err != nil {
+		return err // want `error returned by ext.F is not wrapped`
+	}
+	_, err = ext.G()
+	if err != nil {
+		return err // want `error returned by ext.G is not wrapped`
+	}
+	err = ext.T{}.M()
+	if err != nil {
+		return errors.Wrap(err, "M")
+	}
+	err = ext.F()
+	err = errors.Wrap(err, "F")
+	if err != nil {
+		return err
+	}
+	err = local()
+	if err != nil {
+		return err
+	}
+	f := func() error {
+		return ext.T{}.M() // want `error returned by \(ext.T\).M is not wrapped`
+	}
+	_ = f
+	return ext.F() // want `error returned by ext.F is not wrapped`
+}
+
+func returnWrapTuple() (int, error) {
+	return ext.G() // want `error returned by ext.G is not wrapped`
+}
+
+func local() error {
+	return nil
+}
@@ -0,0 +1,13 @@
+package b
+
+import (
+	"fmt"
+
+	"ext"
+)
+
+var errGlobal = fmt.Errorf("global: %w", ext.F())
+
+func returnWrap() error {
+	return ext.F()
+}
@@ -0,0 +1,7 @@
+package c
+
+import (
+	"github.com/pierrre/errors"
+)
+
+var errGlobal = errors.New("global") // want `errors.New called in a package-level variable initializer, use errbase.New instead`
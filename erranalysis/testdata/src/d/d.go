@@ -0,0 +1,7 @@
+package d
+
+import "github.com/pierrre/errors"
+
+var errGlobal = errors.New("global") // want `errors.New called in a package-level variable initializer, use errbase.New instead`
+
+var errGlobalf = errors.Newf("global %d", 1) // want `errors.Newf called in a package-level variable initializer, use errbase.Newf instead`
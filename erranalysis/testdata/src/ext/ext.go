@@ -0,0 +1,9 @@
+package ext
+
+func F() error { return nil }
+
+func G() (int, error) { return 0, nil }
+
+type T struct{}
+
+func (T) M() error { return nil }
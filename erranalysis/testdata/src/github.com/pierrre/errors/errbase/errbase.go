@@ -0,0 +1,5 @@
+package errbase
+
+func New(msg string) error { return nil }
+
+func Newf(format string, args ...any) error { return nil }
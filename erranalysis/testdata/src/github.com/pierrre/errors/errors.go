@@ -0,0 +1,9 @@
+package errors
+
+func New(msg string) error { return nil }
+
+func Newf(format string, args ...any) error { return nil }
+
+func Wrap(err error, msg string) error { return err }
+
+func Wrapf(err error, format string, args ...any) error { return err }
@@ -0,0 +1,5 @@
+package errtag
+
+func Wrap(err error, key string, val string) error { return err }
+
+func WrapInt(err error, key string, value int) error { return err }
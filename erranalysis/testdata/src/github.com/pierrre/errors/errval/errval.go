@@ -0,0 +1,3 @@
+package errval
+
+func Wrap(err error, key string, val any) error { return err }
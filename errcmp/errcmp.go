@@ -0,0 +1,256 @@
+// Package errcmp provides utilities to compare error trees structurally.
+//
+// Two errors are equal if they have:
+//   - the same join structure
+//   - the same chains of wrapped errors, with the same messages
+//   - the same tags, values, stacks and other verbose messages (see [errverbose.AppendVerbose])
+//
+// The error types are not compared.
+package errcmp
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pierrre/errors/erriter"
+	"github.com/pierrre/errors/errval"
+	"github.com/pierrre/errors/errverbose"
+)
+
+// Options are the options of [Equal] and [Diff].
+type Options struct {
+	// IgnoreStacks ignores the stacks.
+	IgnoreStacks bool
+	// IgnoreValues ignores the values (see the errval package).
+	IgnoreValues bool
+	// ValueEqual compares the values.
+	// If nil, [reflect.DeepEqual] is used.
+	ValueEqual func(a, b any) bool
+}
+
+// Equal returns true if the errors are structurally equal.
+func Equal(a, b error, opts Options) bool {
+	c := &comparer{
+		opts:     opts,
+		maxDiffs: 1,
+	}
+	c.compareRoots(a, b)
+	return len(c.diffs) == 0
+}
+
+// Diff returns a human-readable description of the differences between the errors.
+//
+// For each chain of wrapped errors, only the first difference is reported, because the following errors of the chain are usually shifted.
+// It returns an empty string if the errors are structurally equal.
+func Diff(a, b error, opts Options) string {
+	c := &comparer{
+		opts:     opts,
+		maxDiffs: maxDiffs,
+	}
+	c.compareRoots(a, b)
+	if len(c.diffs) == 0 {
+		return ""
+	}
+	return strings.Join(c.diffs, "\n") + "\n"
+}
+
+const maxDiffs = 10
+
+type chain struct {
+	members  []member
+	children []*chain
+}
+
+type member struct {
+	message string
+	kind    string
+	key     string
+	value   any
+	stack   []uintptr
+	text    string // The tag value, or the verbose message.
+}
+
+const (
+	kindStack   = "stack"
+	kindTag     = "tag"
+	kindValue   = "value"
+	kindVerbose = "verbose"
+	kindCycle   = "<cycle detected>"
+	kindTrunc   = "<truncated>"
+)
+
+func newChain(err error, opts Options) *chain {
+	if err == nil {
+		return nil
+	}
+	// The chains of the current path, indexed by the length of [erriter.Node.Path].
+	// The indexes of the path can't be used, because the nil errors of `Unwrap() []error` are skipped by [erriter.Walk].
+	var chains []*chain
+	erriter.Walk(err, func(n erriter.Node) erriter.WalkAction {
+		if n.Depth == 0 {
+			c := &chain{}
+			if len(chains) > 0 {
+				parent := chains[len(n.Path)-1]
+				parent.children = append(parent.children, c)
+			}
+			chains = append(chains[:len(n.Path)], c)
+		}
+		c := chains[len(n.Path)]
+		switch {
+		case n.Cycle:
+			c.members = append(c.members, member{kind: kindCycle})
+			return erriter.WalkSkip
+		case n.Truncated:
+			c.members = append(c.members, member{kind: kindTrunc})
+			return erriter.WalkSkip
+		}
+		m, ok := newMember(n.Err, opts)
+		if ok {
+			c.members = append(c.members, m)
+		}
+		return erriter.WalkContinue
+	})
+	return chains[0]
+}
+
+func newMember(err error, opts Options) (member, bool) {
+	m := member{
+		message: err.Error(),
+	}
+	switch v := err.(type) { //nolint:errorlint // We want to check for specific error types.
+	case interface{ StackFrames() []uintptr }:
+		if opts.IgnoreStacks {
+			// A stack wrapper that also has its own message (e.g. created by errors.New or errors.Wrap) is kept as a message.
+			_, u := erriter.Unwrap(err)
+			if u != nil && u.Error() == m.message {
+				return m, false
+			}
+			m.kind = kindVerbose
+			return m, true
+		}
+		m.kind = kindStack
+		m.stack = v.StackFrames()
+	case interface{ Tag() (string, string) }:
+		m.kind = kindTag
+		m.key, m.text = v.Tag()
+	case interface{ Value() (string, any) }:
+		if opts.IgnoreValues {
+			return m, false
+		}
+		m.kind = kindValue
+		m.key, m.value = v.Value()
+	default:
+		m.kind = kindVerbose
+		b, _ := errverbose.AppendVerbose(nil, err)
+		m.text = string(b)
+	}
+	return m, true
+}
+
+type comparer struct {
+	opts     Options
+	maxDiffs int
+	diffs    []string
+}
+
+func (c *comparer) compareRoots(a, b error) {
+	if a == nil || b == nil {
+		if a != b { //nolint:errorlint // We want to compare the errors.
+			c.addDiff("error", "nil: "+strconv.FormatBool(a == nil)+" != "+strconv.FormatBool(b == nil))
+		}
+		return
+	}
+	c.compareChains(newChain(a, c.opts), newChain(b, c.opts), nil)
+}
+
+func (c *comparer) compareChains(a, b *chain, path []int) {
+	if len(c.diffs) >= c.maxDiffs {
+		return
+	}
+	loc := location(path)
+	for i := range max(len(a.members), len(b.members)) {
+		mloc := loc + ", wrapper " + strconv.Itoa(i)
+		if i >= len(a.members) || i >= len(b.members) {
+			c.addDiff(mloc, "chain length: "+strconv.Itoa(len(a.members))+" != "+strconv.Itoa(len(b.members)))
+			break
+		}
+		d := c.compareMembers(a.members[i], b.members[i])
+		if d != "" {
+			c.addDiff(mloc, d)
+			break
+		}
+	}
+	if len(a.children) != len(b.children) {
+		c.addDiff(loc, "joined errors: "+strconv.Itoa(len(a.children))+" != "+strconv.Itoa(len(b.children)))
+		return
+	}
+	for i := range a.children {
+		c.compareChains(a.children[i], b.children[i], append(slices.Clip(path), i))
+	}
+}
+
+func (c *comparer) compareMembers(a, b member) string {
+	switch {
+	case a.kind != b.kind:
+		return "kind: " + a.kind + " != " + b.kind
+	case a.message != b.message:
+		return "message: " + strconv.Quote(a.message) + " != " + strconv.Quote(b.message)
+	case a.key != b.key:
+		return a.kind + " key: " + strconv.Quote(a.key) + " != " + strconv.Quote(b.key)
+	}
+	switch a.kind {
+	case kindStack:
+		if !slices.Equal(a.stack, b.stack) {
+			return "stack: different frames"
+		}
+	case kindTag:
+		if a.text != b.text {
+			return "tag " + strconv.Quote(a.key) + ": " + strconv.Quote(a.text) + " != " + strconv.Quote(b.text)
+		}
+	case kindValue:
+		if !c.valueEqual(a.value, b.value) {
+			return "value " + strconv.Quote(a.key) + ": " + valueString(a.value) + " != " + valueString(b.value)
+		}
+	case kindVerbose:
+		if a.text != b.text {
+			return "verbose: " + strconv.Quote(a.text) + " != " + strconv.Quote(b.text)
+		}
+	}
+	return ""
+}
+
+func (c *comparer) valueEqual(a, b any) bool {
+	if c.opts.ValueEqual != nil {
+		return c.opts.ValueEqual(a, b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func valueString(v any) string {
+	var sb strings.Builder
+	errval.VerboseWriter.Load()(&sb, v)
+	return sb.String()
+}
+
+func (c *comparer) addDiff(loc string, d string) {
+	if len(c.diffs) < c.maxDiffs {
+		c.diffs = append(c.diffs, loc+": "+d)
+	}
+}
+
+func location(path []int) string {
+	if len(path) == 0 {
+		return "error"
+	}
+	var sb strings.Builder
+	sb.WriteString("sub error ")
+	for i, d := range path {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(strconv.Itoa(d))
+	}
+	return sb.String()
+}
@@ -0,0 +1,133 @@
+package errcmp_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pierrre/assert"
+	"github.com/pierrre/errors"
+	"github.com/pierrre/errors/errbase"
+	. "github.com/pierrre/errors/errcmp"
+	"github.com/pierrre/errors/errjoin"
+	"github.com/pierrre/errors/errmsg"
+	"github.com/pierrre/errors/errstack"
+	"github.com/pierrre/errors/errtag"
+	"github.com/pierrre/errors/errtmp"
+	"github.com/pierrre/errors/errval"
+)
+
+func Example() {
+	a := errtag.Wrap(errbase.New("error"), "foo", "bar")
+	b := errtag.Wrap(errbase.New("error"), "foo", "baz")
+	fmt.Println(Equal(a, b, Options{}))
+	fmt.Print(Diff(a, b, Options{}))
+	// Output:
+	// false
+	// error, wrapper 0: tag "foo": "bar" != "baz"
+}
+
+func newTestError(tag string, val any) error {
+	err := errjoin.Join(
+		errbase.New("error a"),
+		errmsg.Wrap(errbase.New("error b"), "wrap"),
+	)
+	err = errtag.Wrap(err, "foo", tag)
+	err = errval.Wrap(err, "count", val)
+	err = errtmp.Wrap(err, true)
+	return err
+}
+
+func TestEqual(t *testing.T) {
+	assert.True(t, Equal(newTestError("bar", 1), newTestError("bar", 1), Options{}))
+	assert.False(t, Equal(newTestError("bar", 1), newTestError("baz", 1), Options{}))
+	assert.False(t, Equal(newTestError("bar", 1), newTestError("bar", 2), Options{}))
+}
+
+func TestEqualNil(t *testing.T) {
+	assert.True(t, Equal(nil, nil, Options{}))
+	assert.False(t, Equal(errbase.New("error"), nil, Options{}))
+	assert.Equal(t, Diff(nil, errbase.New("error"), Options{}), "error: nil: true != false\n")
+}
+
+func TestEqualIgnoreValues(t *testing.T) {
+	assert.True(t, Equal(newTestError("bar", 1), newTestError("bar", 2), Options{IgnoreValues: true}))
+	assert.True(t, Equal(errbase.New("error"), errval.Wrap(errbase.New("error"), "foo", 1), Options{IgnoreValues: true}))
+}
+
+func TestEqualValueEqual(t *testing.T) {
+	opts := Options{
+		ValueEqual: func(a, b any) bool {
+			return true
+		},
+	}
+	assert.True(t, Equal(newTestError("bar", 1), newTestError("bar", 2), opts))
+}
+
+func newStackError() error {
+	return errors.New("error")
+}
+
+func TestEqualStacks(t *testing.T) {
+	a := errors.New("error")
+	b := errors.New("error")
+	assert.False(t, Equal(a, b, Options{}))
+	assert.True(t, Equal(a, b, Options{IgnoreStacks: true}))
+	assert.True(t, Equal(a, errbase.New("error"), Options{IgnoreStacks: true}))
+	assert.True(t, Equal(errors.Wrap(errbase.New("error"), "test"), errmsg.Wrap(errbase.New("error"), "test"), Options{IgnoreStacks: true}))
+	assert.True(t, Equal(errors.Wrap(errbase.New("error"), "test"), errmsg.Wrap(errstack.Wrap(errbase.New("error")), "test"), Options{IgnoreStacks: true}))
+	assert.Equal(t, Diff(a, b, Options{}), "error, wrapper 0: stack: different frames\n")
+	var errs []error
+	for range 2 {
+		errs = append(errs, newStackError())
+	}
+	assert.True(t, Equal(errs[0], errs[1], Options{}))
+}
+
+func TestDiff(t *testing.T) {
+	a := newTestError("bar", 1)
+	assert.Equal(t, Diff(a, a, Options{}), "")
+	b := errtmp.Wrap(errjoin.Join(errbase.New("error a"), errbase.New("error c")), false)
+	assert.Equal(t, Diff(a, b, Options{}), `error, wrapper 0: message: "error a\nwrap: error b" != "error a\nerror c"
+sub error 1, wrapper 0: message: "wrap: error b" != "error c"
+`)
+	b = newTestError("bar", 2)
+	assert.Equal(t, Diff(a, b, Options{}), `error, wrapper 1: value "count": [int] 1 != [int] 2
+`)
+	b = errtmp.Wrap(errval.Wrap(errtag.Wrap(a, "foo", "bar"), "count", 1), false)
+	assert.Equal(t, Diff(errtmp.Wrap(a, true), b, Options{}), `error, wrapper 0: verbose: "temporary = true" != "temporary = false"
+`)
+	b = errtag.Wrap(errtmp.Wrap(a, true), "foo", "bar")
+	assert.Equal(t, Diff(errtmp.Wrap(a, true), b, Options{}), `error, wrapper 0: kind: verbose != tag
+`)
+}
+
+func TestDiffStructure(t *testing.T) {
+	a := errjoin.Join(errbase.New("error"), errbase.New("error"))
+	b := errjoin.Join(errbase.New("error"), errbase.New("error"), errbase.New("error"))
+	assert.Equal(t, Diff(errmsg.Wrap(a, "x"), errmsg.Wrap(b, "x"), Options{}), `error, wrapper 0: message: "x: error\nerror" != "x: error\nerror\nerror"
+error: joined errors: 2 != 3
+`)
+	assert.Equal(t, Diff(errbase.New("error"), errmsg.Wrap(errbase.New("error"), "x"), Options{}), `error, wrapper 0: message: "error" != "x: error"
+`)
+}
+
+type testNilJoinError struct {
+	errs []error
+}
+
+func (e *testNilJoinError) Error() string {
+	return "join"
+}
+
+func (e *testNilJoinError) Unwrap() []error {
+	return e.errs
+}
+
+func TestEqualNilJoined(t *testing.T) {
+	a := &testNilJoinError{errs: []error{nil, errmsg.Wrap(errbase.New("error"), "a"), nil, errbase.New("error")}}
+	b := &testNilJoinError{errs: []error{errmsg.Wrap(errbase.New("error"), "a"), errbase.New("error")}}
+	assert.True(t, Equal(a, b, Options{}))
+	c := &testNilJoinError{errs: []error{nil, errmsg.Wrap(errbase.New("error"), "c"), errbase.New("error")}}
+	assert.Equal(t, Diff(a, c, Options{}), `sub error 0, wrapper 0: message: "a: error" != "c: error"
+`)
+}
@@ -0,0 +1,181 @@
+// Package errcolor provides utilities to write error verbose messages with ANSI colors.
+//
+// The output is written by [errverbose.WriteStyle], so it has the same structure and limits as [errverbose.Write]:
+//   - the messages are bold and red
+//   - the tags and values are in distinct colors
+//   - the stack frames of the main module (see [MainModule]) are highlighted, the other frames and the file:line locations are dimmed
+package errcolor
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/pierrre/errors/errverbose"
+	"github.com/pierrre/go-libs/bytesutil"
+	"github.com/pierrre/go-libs/syncutil/atomicutil"
+)
+
+// ANSI escape codes.
+const (
+	colorReset   = "\x1b[0m"
+	colorMessage = "\x1b[1;31m"
+	colorTag     = "\x1b[36m"
+	colorValue   = "\x1b[35m"
+	colorMain    = "\x1b[1m"
+	colorDim     = "\x1b[2m"
+)
+
+// MainModule is the path of the main module.
+// The stack frames of its packages are highlighted.
+//
+// The default value is the main module path from [debug.ReadBuildInfo].
+var MainModule atomicutil.Value[string]
+
+func init() {
+	bi, ok := debug.ReadBuildInfo()
+	if ok {
+		MainModule.Store(bi.Main.Path)
+	}
+}
+
+// Enabled returns true if colors should be written to the writer.
+//
+// It returns false if the NO_COLOR environment variable is set (see https://no-color.org), if the TERM environment variable is "dumb", or if the writer is not a terminal.
+func Enabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Write writes the error's verbose message to the writer.
+//
+// If colors are not enabled for the writer (see [Enabled]), it calls [errverbose.Write].
+func Write(w io.Writer, err error) {
+	if !Enabled(w) {
+		errverbose.Write(w, err)
+		return
+	}
+	bw := bytesWriterPool.Get()
+	defer bytesWriterPool.Put(bw)
+	*bw = Append(*bw, err)
+	_, _ = w.Write(*bw)
+}
+
+var bytesWriterPool = &bytesutil.WriterPool{}
+
+// String returns the error's verbose message with colors.
+func String(err error) string {
+	bw := bytesWriterPool.Get()
+	defer bytesWriterPool.Put(bw)
+	*bw = Append(*bw, err)
+	return bw.String()
+}
+
+// Append appends the error's verbose message with colors to b.
+//
+// Colors are always written.
+// It uses [errverbose.WriteStyle], so the output is limited by [errverbose.DefaultLimits].
+func Append(b []byte, err error) []byte {
+	bw := bytesutil.Writer(b)
+	errverbose.WriteStyle(&bw, err, errverbose.DefaultLimits.Load(), &errverbose.Style{
+		Message: appendMessage,
+		Verbose: newAppendVerbose(MainModule.Load()),
+		Note:    appendNote,
+	})
+	return bw
+}
+
+func appendMessage(b []byte, msg []byte) []byte {
+	return appendColorLines(b, colorMessage, msg)
+}
+
+func appendNote(b []byte, note []byte) []byte {
+	return appendColorLines(b, colorDim, note)
+}
+
+func newAppendVerbose(mainModule string) func(b []byte, err error, verbose []byte) []byte {
+	return func(b []byte, err error, verbose []byte) []byte {
+		switch err.(type) { //nolint:errorlint // We want to check for specific error types.
+		case interface{ StackFrames() []uintptr }:
+			if bytes.HasPrefix(verbose, stackHeader) {
+				return appendStack(b, verbose, mainModule)
+			}
+		case interface{ Tag() (string, string) }:
+			return appendColorLines(b, colorTag, verbose)
+		case interface{ Value() (string, any) }:
+			return appendColorLines(b, colorValue, verbose)
+		}
+		return append(b, verbose...)
+	}
+}
+
+var stackHeader = []byte("stack:\n")
+
+// appendStack appends the verbose message of a stack (see [errstack.AppendVerbose]) with colors.
+//
+// The functions of the main module are highlighted.
+// The other lines (other functions, file:line locations, source lines and notes) are dimmed, and their indentation is not colored.
+func appendStack(b []byte, verbose []byte, mainModule string) []byte {
+	b = append(b, stackHeader...)
+	for line := range bytes.Lines(verbose[len(stackHeader):]) {
+		line, found := bytes.CutSuffix(line, newline)
+		content := bytes.TrimLeft(line, "\t")
+		b = append(b, line[:len(line)-len(content)]...)
+		color := colorDim
+		if len(content) == len(line) && isMainFunction(string(line), mainModule) {
+			color = colorMain
+		}
+		b = appendColorLines(b, color, content)
+		if found {
+			b = append(b, '\n')
+		}
+	}
+	return b
+}
+
+var newline = []byte("\n")
+
+// appendColorLines appends s with the color.
+//
+// Each line is colored separately, so the color doesn't span multiple lines (see [errverbose.Style]).
+func appendColorLines(b []byte, color string, s []byte) []byte {
+	for i := 0; ; i++ {
+		line, rest, found := bytes.Cut(s, newline)
+		if i > 0 {
+			b = append(b, '\n')
+		}
+		if len(line) > 0 {
+			b = append(b, color...)
+			b = append(b, line...)
+			b = append(b, colorReset...)
+		}
+		if !found {
+			return b
+		}
+		s = rest
+	}
+}
+
+// isMainFunction returns true if the function belongs to the main package or to a package of the main module.
+func isMainFunction(function string, mainModule string) bool {
+	if strings.HasPrefix(function, "main.") {
+		return true
+	}
+	if mainModule == "" || !strings.HasPrefix(function, mainModule) {
+		return false
+	}
+	rest := function[len(mainModule):]
+	return strings.HasPrefix(rest, "/") || strings.HasPrefix(rest, ".")
+}
@@ -0,0 +1,144 @@
+package errcolor_test
+
+import (
+	"bytes"
+	std_errors "errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pierrre/assert"
+	"github.com/pierrre/errors/errbase"
+	. "github.com/pierrre/errors/errcolor"
+	"github.com/pierrre/errors/errstack"
+	"github.com/pierrre/errors/errtag"
+	"github.com/pierrre/errors/errval"
+	"github.com/pierrre/errors/errverbose"
+)
+
+func ExampleString() {
+	err := errbase.New("error")
+	err = errtag.Wrap(err, "foo", "bar")
+	s := String(err)
+	fmt.Printf("%q\n", s)
+	// Output: "\x1b[1;31merror\x1b[0m\n\x1b[36mtag foo = bar\x1b[0m\n"
+}
+
+func TestString(t *testing.T) {
+	err := errbase.New("error")
+	err = errval.Wrap(err, "foo", "bar")
+	err = errtag.Wrap(err, "aaa", "zzz")
+	s := String(err)
+	assert.Equal(t, s, "\x1b[1;31merror\x1b[0m\n\x1b[36mtag aaa = zzz\x1b[0m\n\x1b[35mvalue foo = [string] (len=3) \"bar\"\x1b[0m\n")
+}
+
+func TestStringStack(t *testing.T) {
+	previous := MainModule.Swap("github.com/pierrre/errors")
+	defer MainModule.Store(previous)
+	err := errbase.New("error")
+	err = errstack.Wrap(err)
+	s := String(err)
+	assert.StringHasPrefix(t, s, "\x1b[1;31merror\x1b[0m\nstack:\n\x1b[1mgithub.com/pierrre/errors/errcolor_test.TestStringStack\x1b[0m\n\t\x1b[2m")
+	assert.StringContains(t, s, "\x1b[2mtesting.tRunner\x1b[0m\n")
+}
+
+func TestStringStackNoMainModule(t *testing.T) {
+	previous := MainModule.Swap("")
+	defer MainModule.Store(previous)
+	err := errbase.New("error")
+	err = errstack.Wrap(err)
+	s := String(err)
+	assert.StringContains(t, s, "\x1b[2mgithub.com/pierrre/errors/errcolor_test.TestStringStackNoMainModule\x1b[0m\n")
+}
+
+func TestStringJoin(t *testing.T) {
+	err := std_errors.Join(
+		errbase.New("error a"),
+		errbase.New("error b"),
+	)
+	s := String(err)
+	assert.Equal(t, s, "\x1b[1;31merror a\x1b[0m\n\x1b[1;31merror b\x1b[0m\n\nSub error 0: \x1b[1;31merror a\x1b[0m\n\nSub error 1: \x1b[1;31merror b\x1b[0m\n")
+}
+
+func TestStringSameAsVerbose(t *testing.T) {
+	previous := errstack.VerboseSource.Swap(&errstack.SourceReader{})
+	defer errstack.VerboseSource.Store(previous)
+	err := std_errors.Join(
+		errtag.Wrap(errstack.Wrap(errbase.New("error a")), "foo", "bar"),
+		errval.Wrap(errbase.New("error b\nline"), "foo", "bar"),
+	)
+	s := String(err)
+	assert.Equal(t, ansiRegexp.ReplaceAllString(s, ""), errverbose.String(err))
+}
+
+var ansiRegexp = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestStringStackCustomVerbose(t *testing.T) {
+	err := &customStackError{}
+	s := String(err)
+	assert.Equal(t, s, "\x1b[1;31merror\x1b[0m\ncustom stack\n")
+}
+
+type customStackError struct{}
+
+func (*customStackError) Error() string {
+	return "error"
+}
+
+func (*customStackError) StackFrames() []uintptr {
+	return nil
+}
+
+func (*customStackError) ErrorVerbose() string {
+	return "custom stack"
+}
+
+func TestStringLimits(t *testing.T) {
+	previous := errverbose.DefaultLimits.Swap(errverbose.Limits{MaxJoinErrors: 1})
+	defer errverbose.DefaultLimits.Store(previous)
+	err := std_errors.Join(
+		errbase.New("error a"),
+		errbase.New("error b"),
+	)
+	s := String(err)
+	assert.StringHasSuffix(t, s, "\nSub error 0: \x1b[1;31merror a\x1b[0m\n\n\x1b[2m... and 1 more error\x1b[0m\n")
+}
+
+func TestStringNil(t *testing.T) {
+	s := String(nil)
+	assert.Equal(t, s, "<nil>\n")
+}
+
+func TestWriteNotEnabled(t *testing.T) {
+	err := errbase.New("error")
+	err = errtag.Wrap(err, "foo", "bar")
+	buf := new(bytes.Buffer)
+	Write(buf, err)
+	assert.Equal(t, buf.String(), errverbose.String(err))
+}
+
+func TestEnabled(t *testing.T) {
+	assert.False(t, Enabled(new(strings.Builder)))
+}
+
+func TestEnabledNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, Enabled(os.Stdout))
+}
+
+func TestEnabledFile(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "")
+	assert.NoError(t, err)
+	defer f.Close() //nolint:errcheck // Test.
+	assert.False(t, Enabled(f))
+}
+
+func TestStringSource(t *testing.T) {
+	previous := errstack.VerboseSource.Swap(&errstack.SourceReader{})
+	defer errstack.VerboseSource.Store(previous)
+	err := errstack.Wrap(errbase.New("error")) // Source line.
+	s := String(err)
+	assert.RegexpMatch(t, "\t\t\x1b\\[2m> \\d+ \\| \terr := errstack.Wrap\\(errbase.New\\(\"error\"\\)\\) // Source line.\x1b\\[0m\n", s)
+}
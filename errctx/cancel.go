@@ -0,0 +1,115 @@
+package errctx
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pierrre/errors/errappend"
+	"github.com/pierrre/errors/errignore"
+	"github.com/pierrre/errors/erriter"
+	"github.com/pierrre/errors/errstack"
+	"github.com/pierrre/errors/errval"
+	"github.com/pierrre/errors/errverbose"
+)
+
+// WithCancelCause calls [context.WithCancelCause].
+//
+// The returned [context.CancelCauseFunc] adds the stack of its caller to the cause (see [errstack.Ensure]).
+// If the cause is nil, [context.Canceled] is used.
+func WithCancelCause(parent context.Context) (context.Context, context.CancelCauseFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	return ctx, func(cause error) {
+		if cause == nil {
+			cause = context.Canceled
+		}
+		cause = errstack.EnsureSkip(cause, 1)
+		cancel(cause)
+	}
+}
+
+// WithTimeout calls [context.WithTimeoutCause].
+//
+// The cause is [context.DeadlineExceeded] with the stack of the caller.
+func WithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(parent, timeout, errstack.WrapSkip(context.DeadlineExceeded, 1))
+}
+
+// WithDeadline calls [context.WithDeadlineCause].
+//
+// The cause is [context.DeadlineExceeded] with the stack of the caller.
+func WithDeadline(parent context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	return context.WithDeadlineCause(parent, deadline, errstack.WrapSkip(context.DeadlineExceeded, 1))
+}
+
+// Err returns the error of a [context.Context] ([context.Context.Err]), with its cause ([context.Cause]).
+//
+// The stack of the caller is added if the error doesn't already have one (see [errstack.Ensure]), e.g. the cause set by [WithTimeout] or [WithCancelCause].
+// If the context has a deadline, it is added as the value "context_deadline" (see [errval.Wrap]).
+// The returned error matches [context.Canceled] or [context.DeadlineExceeded] with [errors.Is].
+// It returns nil if the context is not done.
+func Err(ctx context.Context) error {
+	err := ctx.Err()
+	if err == nil {
+		return nil
+	}
+	cause := context.Cause(ctx)
+	if cause != nil && cause != err { //nolint:errorlint // We want to check if the cause is the same error.
+		if erriter.Is(cause, err) {
+			err = cause
+		} else {
+			err = &causeError{
+				error: err,
+				cause: cause,
+			}
+		}
+	}
+	err = errstack.EnsureSkip(err, 1)
+	deadline, ok := ctx.Deadline()
+	if ok {
+		err = errval.Wrap(err, "context_deadline", deadline)
+	}
+	return err
+}
+
+type causeError struct {
+	error
+	cause error
+}
+
+func (err *causeError) Unwrap() []error {
+	return []error{err.error, err.cause}
+}
+
+func (err *causeError) Format(s fmt.State, verb rune) {
+	errverbose.Format(s, verb, err)
+}
+
+func (err *causeError) Error() string {
+	return errappend.String(err)
+}
+
+func (err *causeError) ErrorAppend(b []byte) []byte {
+	b = errappend.Append(b, err.error)
+	b = append(b, ": "...)
+	b = errappend.Append(b, err.cause)
+	return b
+}
+
+// IsCanceled returns true if an error is caused by a [context.Context] cancellation ([context.Canceled]), false otherwise.
+//
+// It returns false for [context.DeadlineExceeded].
+func IsCanceled(err error) bool {
+	return erriter.Is(err, context.Canceled)
+}
+
+// IgnoreCanceled marks an error as ignored (see [errignore.Wrap]) if it is caused by a [context.Context] cancellation (see [IsCanceled]).
+//
+// It is useful to filter the noise caused by cancellations (e.g. during shutdown) from the logs.
+// Otherwise, it returns err.
+func IgnoreCanceled(err error) error {
+	if IsCanceled(err) {
+		err = errignore.Wrap(err)
+	}
+	return err
+}
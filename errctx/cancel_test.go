@@ -0,0 +1,126 @@
+package errctx_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pierrre/assert"
+	"github.com/pierrre/errors"
+	"github.com/pierrre/errors/errbase"
+	. "github.com/pierrre/errors/errctx"
+	"github.com/pierrre/errors/errignore"
+	"github.com/pierrre/errors/errstack"
+	"github.com/pierrre/errors/errtmp"
+	"github.com/pierrre/errors/errval"
+)
+
+func ExampleErr() {
+	ctx, cancel := WithCancelCause(context.Background())
+	cancel(errbase.New("shutdown"))
+	err := Err(ctx)
+	fmt.Println(err)
+	fmt.Println(errors.Is(err, context.Canceled))
+	// Output:
+	// context canceled: shutdown
+	// true
+}
+
+func TestWithCancelCause(t *testing.T) {
+	ctx, cancel := WithCancelCause(t.Context())
+	cancel(errbase.New("error"))
+	cause := context.Cause(ctx)
+	assert.ErrorEqual(t, cause, "error")
+	sfs := slices.Collect(errstack.Frames(cause))
+	assert.SliceLen(t, sfs, 1)
+	fs := slices.Collect(sfs[0])
+	assert.SliceNotEmpty(t, fs)
+	assert.Equal(t, fs[0].Function, "github.com/pierrre/errors/errctx_test.TestWithCancelCause")
+}
+
+func TestWithCancelCauseNil(t *testing.T) {
+	ctx, cancel := WithCancelCause(t.Context())
+	cancel(nil)
+	cause := context.Cause(ctx)
+	assert.ErrorIs(t, cause, context.Canceled)
+	sfs := slices.Collect(errstack.Frames(cause))
+	assert.SliceLen(t, sfs, 1)
+}
+
+func TestWithTimeout(t *testing.T) {
+	ctx, cancel := WithTimeout(t.Context(), -1)
+	defer cancel()
+	cause := context.Cause(ctx)
+	assert.ErrorIs(t, cause, context.DeadlineExceeded)
+	sfs := slices.Collect(errstack.Frames(cause))
+	assert.SliceLen(t, sfs, 1)
+}
+
+func TestWithDeadline(t *testing.T) {
+	ctx, cancel := WithDeadline(t.Context(), time.Now().Add(-1*time.Second))
+	defer cancel()
+	cause := context.Cause(ctx)
+	assert.ErrorIs(t, cause, context.DeadlineExceeded)
+	sfs := slices.Collect(errstack.Frames(cause))
+	assert.SliceLen(t, sfs, 1)
+}
+
+func TestErr(t *testing.T) {
+	ctx, cancel := WithCancelCause(t.Context())
+	cancel(errbase.New("error"))
+	err := Err(ctx)
+	assert.ErrorEqual(t, err, "context canceled: error")
+	assert.ErrorIs(t, err, context.Canceled)
+	sfs := slices.Collect(errstack.Frames(err))
+	assert.SliceLen(t, sfs, 1)
+	assert.False(t, errtmp.Is(err))
+}
+
+func TestErrNotDone(t *testing.T) {
+	err := Err(t.Context())
+	assert.NoError(t, err)
+}
+
+func TestErrNoCause(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	err := Err(ctx)
+	assert.ErrorEqual(t, err, "context canceled")
+	assert.ErrorIs(t, err, context.Canceled)
+	sfs := slices.Collect(errstack.Frames(err))
+	assert.SliceLen(t, sfs, 1)
+}
+
+func TestErrDeadline(t *testing.T) {
+	deadline := time.Now().Add(-1 * time.Second)
+	ctx, cancel := WithDeadline(t.Context(), deadline)
+	defer cancel()
+	err := Err(ctx)
+	assert.ErrorEqual(t, err, "context deadline exceeded")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	v, ok := errval.GetValue(err, "context_deadline")
+	assert.True(t, ok)
+	assert.Equal(t, v, any(deadline))
+	sfs := slices.Collect(errstack.Frames(err))
+	assert.SliceLen(t, sfs, 1)
+	assert.True(t, errtmp.Is(err))
+}
+
+func TestIgnoreCanceled(t *testing.T) {
+	err := errors.Wrap(context.Canceled, "test")
+	err = IgnoreCanceled(err)
+	assert.True(t, errignore.Is(err))
+}
+
+func TestIgnoreCanceledDeadlineExceeded(t *testing.T) {
+	err := errors.Wrap(context.DeadlineExceeded, "test")
+	err = IgnoreCanceled(err)
+	assert.False(t, errignore.Is(err))
+}
+
+func TestIgnoreCanceledNil(t *testing.T) {
+	err := IgnoreCanceled(nil)
+	assert.NoError(t, err)
+}
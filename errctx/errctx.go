@@ -0,0 +1,187 @@
+// Package errctx provides a way to store attributes in a [context.Context] and add them to errors.
+//
+// It is useful for values that are scoped to a request (request ID, user ID, trace ID, etc.), and that should be added to all errors created during this request.
+package errctx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/pierrre/errors/errappend"
+	"github.com/pierrre/errors/errslog"
+	"github.com/pierrre/errors/errtag"
+	"github.com/pierrre/errors/errverbose"
+)
+
+type attrsContextKey struct{}
+
+// WithAttrs returns a new [context.Context] with the given attributes added to the existing ones.
+//
+// If an attribute with the same key already exists in the context, it is replaced.
+// If attrs is empty, it returns ctx.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	if len(attrs) == 0 {
+		return ctx
+	}
+	existing := Attrs(ctx)
+	newAttrs := make([]slog.Attr, 0, len(existing)+len(attrs))
+	for _, attr := range existing {
+		if !containsKey(attrs, attr.Key) {
+			newAttrs = append(newAttrs, attr)
+		}
+	}
+	for i, attr := range attrs {
+		if !containsKey(attrs[i+1:], attr.Key) {
+			newAttrs = append(newAttrs, attr)
+		}
+	}
+	return context.WithValue(ctx, attrsContextKey{}, newAttrs)
+}
+
+// Attrs returns the attributes stored in a [context.Context] by [WithAttrs].
+//
+// The returned slice must not be modified.
+func Attrs(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsContextKey{}).([]slog.Attr)
+	return attrs
+}
+
+// Wrap adds the attributes stored in a [context.Context] to an error.
+//
+// Contrary to [errslog.WrapAttrs], the error's message is not changed.
+// The attributes are returned by [errslog.AllAttrs] and [errslog.GetAttrs], and are shown in the verbose message.
+// Attributes whose key already exists in the error tree are not added again.
+// It returns nil if err is nil.
+// If there is no attribute to add, it returns err.
+func Wrap(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	attrs := missingAttrs(Attrs(ctx), err, nil)
+	if len(attrs) == 0 {
+		return err
+	}
+	return &attrsError{
+		error: err,
+		attrs: attrs,
+	}
+}
+
+type attrsError struct {
+	error
+	attrs []slog.Attr
+}
+
+func (err *attrsError) Unwrap() error {
+	return err.error
+}
+
+func (err *attrsError) Format(s fmt.State, verb rune) {
+	errverbose.Format(s, verb, err)
+}
+
+func (err *attrsError) ErrorAppend(b []byte) []byte {
+	return errappend.Append(b, err.error)
+}
+
+func (err *attrsError) ErrorVerboseAppend(b []byte) []byte {
+	for i, attr := range err.attrs {
+		if i > 0 {
+			b = append(b, '\n')
+		}
+		b = append(b, "attr "...)
+		b = append(b, attr.Key...)
+		b = append(b, " = "...)
+		b = append(b, attr.Value.String()...)
+	}
+	return b
+}
+
+func (err *attrsError) SlogAttrs() []slog.Attr {
+	return err.attrs
+}
+
+// WrapTags adds the attributes stored in a [context.Context] to an error, with [errtag.Wrap].
+//
+// The tag value is the string representation of the attribute value.
+// Attributes whose key already exists as a tag in the error tree are not added again.
+// It returns nil if err is nil.
+func WrapTags(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	attrs := Attrs(ctx)
+	if len(attrs) == 0 {
+		return err
+	}
+	tags := errtag.Get(err)
+	for _, attr := range slices.Backward(attrs) {
+		if _, ok := tags[attr.Key]; ok {
+			continue
+		}
+		err = errtag.Wrap(err, attr.Key, attr.Value.String())
+	}
+	return err
+}
+
+// Log calls [LoggerLog] with [slog.Default].
+func Log(ctx context.Context, err error, attrs ...slog.Attr) {
+	LoggerLog(ctx, nil, err, attrs...)
+}
+
+// LoggerLog calls [errslog.LoggerLog] with the attributes stored in the [context.Context] added to attrs.
+//
+// The attributes of the context whose key already exists in attrs or in the error tree are not added, so the attributes are not duplicated.
+func LoggerLog(ctx context.Context, logger *slog.Logger, err error, attrs ...slog.Attr) {
+	if err == nil {
+		return
+	}
+	ctxAttrs := missingAttrs(Attrs(ctx), err, attrs)
+	if len(ctxAttrs) > 0 {
+		attrs = slices.Concat(attrs, ctxAttrs)
+	}
+	errslog.LoggerLog(ctx, logger, err, attrs...)
+}
+
+// missingAttrs returns the attributes whose key doesn't exist in the error tree or in the excluded attributes.
+//
+// It returns attrs if all the attributes are missing, in order to avoid an allocation.
+func missingAttrs(attrs []slog.Attr, err error, exclude []slog.Attr) []slog.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	var res []slog.Attr
+	for i, attr := range attrs {
+		if !containsKey(exclude, attr.Key) && !errContainsKey(err, attr.Key) {
+			if res != nil {
+				res = append(res, attr)
+			}
+			continue
+		}
+		if res == nil {
+			res = make([]slog.Attr, i, len(attrs)-1)
+			copy(res, attrs[:i])
+		}
+	}
+	if res == nil {
+		return attrs
+	}
+	return res
+}
+
+func errContainsKey(err error, key string) bool {
+	for attr := range errslog.AllAttrs(err) {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func containsKey(attrs []slog.Attr, key string) bool {
+	return slices.ContainsFunc(attrs, func(a slog.Attr) bool {
+		return a.Key == key
+	})
+}
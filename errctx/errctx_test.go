@@ -0,0 +1,220 @@
+package errctx_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/pierrre/assert"
+	"github.com/pierrre/errors/errbase"
+	. "github.com/pierrre/errors/errctx"
+	"github.com/pierrre/errors/errslog"
+	"github.com/pierrre/errors/errtag"
+	"github.com/pierrre/errors/errverbose"
+	"github.com/pierrre/go-libs/bytesutil"
+)
+
+var testSink any
+
+func Example() {
+	ctx := context.Background()
+	ctx = WithAttrs(ctx, slog.String("request_id", "123"))
+	err := errbase.New("error")
+	err = Wrap(ctx, err)
+	attrs := errslog.GetAttrs(err)
+	fmt.Println(attrs[0])
+	// Output: request_id=123
+}
+
+func TestWithAttrs(t *testing.T) {
+	ctx := t.Context()
+	ctx = WithAttrs(ctx, slog.String("a", "1"), slog.String("b", "2"))
+	ctx = WithAttrs(ctx, slog.String("a", "3"), slog.String("c", "4"))
+	attrs := Attrs(ctx)
+	assert.DeepEqual(t, attrs, []slog.Attr{
+		slog.String("b", "2"),
+		slog.String("a", "3"),
+		slog.String("c", "4"),
+	})
+}
+
+func TestWithAttrsDuplicate(t *testing.T) {
+	ctx := t.Context()
+	ctx = WithAttrs(ctx, slog.String("a", "1"), slog.String("a", "2"))
+	attrs := Attrs(ctx)
+	assert.DeepEqual(t, attrs, []slog.Attr{
+		slog.String("a", "2"),
+	})
+}
+
+func TestWithAttrsEmpty(t *testing.T) {
+	ctx := t.Context()
+	ctx2 := WithAttrs(ctx)
+	assert.Equal(t, ctx2, ctx)
+}
+
+func TestAttrsEmpty(t *testing.T) {
+	ctx := t.Context()
+	attrs := Attrs(ctx)
+	assert.SliceEmpty(t, attrs)
+}
+
+func TestWrap(t *testing.T) {
+	ctx := t.Context()
+	ctx = WithAttrs(ctx, slog.String("a", "1"), slog.Int("b", 2))
+	err := errbase.New("error")
+	err = Wrap(ctx, err)
+	assert.ErrorEqual(t, err, "error")
+	attrs := errslog.GetAttrs(err)
+	assert.DeepEqual(t, attrs, []slog.Attr{
+		slog.String("a", "1"),
+		slog.Int("b", 2),
+	})
+}
+
+func TestWrapExisting(t *testing.T) {
+	ctx := t.Context()
+	ctx = WithAttrs(ctx, slog.String("a", "1"), slog.Int("b", 2))
+	err := errbase.New("error")
+	err = errslog.WrapAttrs(err, slog.String("a", "0"))
+	err = Wrap(ctx, err)
+	err = Wrap(ctx, err)
+	assert.ErrorEqual(t, err, "a=\"0\": error")
+	attrs := errslog.GetAttrs(err)
+	assert.DeepEqual(t, attrs, []slog.Attr{
+		slog.Int("b", 2),
+		slog.String("a", "0"),
+	})
+}
+
+func TestWrapVerbose(t *testing.T) {
+	ctx := t.Context()
+	ctx = WithAttrs(ctx, slog.String("a", "1"), slog.Int("b", 2))
+	err := errbase.New("error")
+	err = Wrap(ctx, err)
+	assert.Equal(t, errverbose.String(err), "error\nattr a = 1\nattr b = 2\n")
+}
+
+func TestWrapNil(t *testing.T) {
+	ctx := t.Context()
+	ctx = WithAttrs(ctx, slog.String("a", "1"))
+	err := Wrap(ctx, nil)
+	assert.NoError(t, err)
+}
+
+func TestWrapNoAttrs(t *testing.T) {
+	ctx := t.Context()
+	err := errbase.New("error")
+	err2 := Wrap(ctx, err)
+	assert.Equal(t, err2, err)
+}
+
+func TestWrapAllocs(t *testing.T) {
+	ctx := t.Context()
+	ctx = WithAttrs(ctx, slog.String("a", "1"), slog.Int("b", 2))
+	err := errbase.New("error")
+	var res error
+	assert.AllocsPerRun(t, 100, func() {
+		res = Wrap(ctx, err)
+	}, 1)
+	testSink = res
+}
+
+func BenchmarkWrap(b *testing.B) {
+	ctx := b.Context()
+	ctx = WithAttrs(ctx, slog.String("a", "1"), slog.Int("b", 2))
+	err := errbase.New("error")
+	var res error
+	for b.Loop() {
+		res = Wrap(ctx, err)
+	}
+	testSink = res
+}
+
+func TestWrapTags(t *testing.T) {
+	ctx := t.Context()
+	ctx = WithAttrs(ctx, slog.String("a", "1"), slog.Int("b", 2))
+	err := errbase.New("error")
+	err = errtag.Wrap(err, "a", "0")
+	err = WrapTags(ctx, err)
+	err = WrapTags(ctx, err)
+	assert.ErrorEqual(t, err, "error")
+	tags := errtag.Get(err)
+	assert.MapEqual(t, tags, map[string]string{
+		"a": "0",
+		"b": "2",
+	})
+}
+
+func TestWrapTagsNil(t *testing.T) {
+	ctx := t.Context()
+	ctx = WithAttrs(ctx, slog.String("a", "1"))
+	err := WrapTags(ctx, nil)
+	assert.NoError(t, err)
+}
+
+func TestWrapTagsNoAttrs(t *testing.T) {
+	ctx := t.Context()
+	err := errbase.New("error")
+	err2 := WrapTags(ctx, err)
+	assert.Equal(t, err2, err)
+}
+
+func newTestLogger(bw *bytesutil.Writer) *slog.Logger {
+	now := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return slog.New(slog.NewTextHandler(bw, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				a.Value = slog.TimeValue(now)
+			}
+			return a
+		},
+	}))
+}
+
+func TestLog(t *testing.T) {
+	ctx := t.Context()
+	previousLogger := slog.Default()
+	defer slog.SetDefault(previousLogger)
+	bw := new(bytesutil.Writer)
+	slog.SetDefault(newTestLogger(bw))
+	ctx = WithAttrs(ctx, slog.String("a", "1"), slog.String("b", "2"), slog.String("c", "3"))
+	err := errbase.New("error")
+	err = errslog.WrapAttrs(err, slog.String("a", "0"))
+	Log(ctx, err, slog.String("b", "4"))
+	expected := "time=2026-01-01T00:00:00.000Z level=ERROR msg=\"a=\\\"0\\\": error\" b=4 c=3 a=0\n"
+	assert.Equal(t, bw.String(), expected)
+}
+
+func TestLoggerLogNoContextAttrs(t *testing.T) {
+	ctx := t.Context()
+	bw := new(bytesutil.Writer)
+	logger := newTestLogger(bw)
+	err := errbase.New("error")
+	LoggerLog(ctx, logger, err, slog.String("a", "1"))
+	expected := "time=2026-01-01T00:00:00.000Z level=ERROR msg=error a=1\n"
+	assert.Equal(t, bw.String(), expected)
+}
+
+func TestLoggerLogErrorNil(t *testing.T) {
+	ctx := t.Context()
+	ctx = WithAttrs(ctx, slog.String("a", "1"))
+	bw := new(bytesutil.Writer)
+	logger := newTestLogger(bw)
+	LoggerLog(ctx, logger, nil)
+	assert.Equal(t, bw.String(), "")
+}
+
+func BenchmarkLoggerLog(b *testing.B) {
+	ctx := b.Context()
+	ctx = WithAttrs(ctx, slog.String("a", "1"), slog.Int("b", 2))
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil)) //nolint:sloglint // We can't use DiscardHandler, because we want to write the log.
+	err := errbase.New("error")
+	err = errslog.WrapAttrs(err, slog.String("a", "0"))
+	for b.Loop() {
+		LoggerLog(ctx, logger, err)
+	}
+}
@@ -0,0 +1,254 @@
+// Package errfault provides fault injection, to test the error handling code.
+//
+// The code under test calls [Inject] (or [InjectContext]) at named injection points:
+//
+//	if err := errfault.Inject("db.query"); err != nil {
+//		return err
+//	}
+//
+// In production, no [Injector] is enabled, and [Inject] returns nil.
+// In tests, an [Injector] is enabled with [Enable] (or [NewContext]), and the configured [Fault] errors are returned.
+package errfault
+
+import (
+	"context"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"github.com/pierrre/errors/errbase"
+	"github.com/pierrre/errors/errstack"
+	"github.com/pierrre/errors/errtag"
+	"github.com/pierrre/errors/errtmp"
+	"github.com/pierrre/go-libs/syncutil/atomicutil"
+)
+
+// ErrInjected is the default error returned by an injection point.
+var ErrInjected = errbase.New("injected fault")
+
+// TagKey is the key of the tag containing the name of the injection point.
+const TagKey = "fault"
+
+// Default is the [Injector] used by [Inject].
+//
+// The default value is nil, so [Inject] returns nil.
+var Default atomicutil.Value[*Injector]
+
+// Enable sets a new [Injector] as [Default], and restores the previous value when the test is done.
+//
+// tb is usually a [testing.TB].
+// The tests calling it must not run in parallel.
+func Enable(tb interface{ Cleanup(func()) }) *Injector {
+	inj := new(Injector)
+	old := Default.Swap(inj)
+	tb.Cleanup(func() {
+		Default.Store(old)
+	})
+	return inj
+}
+
+// Inject returns the error of the injection point from the [Default] [Injector].
+//
+// It returns nil if there is no [Default] [Injector].
+func Inject(name string) error {
+	inj := Default.Load()
+	if inj == nil {
+		return nil
+	}
+	return inj.inject(name, 1)
+}
+
+type injectorContextKey struct{}
+
+// NewContext returns a new [context.Context] containing the [Injector].
+//
+// It allows to use a distinct [Injector] per test, including in parallel tests.
+func NewContext(ctx context.Context, inj *Injector) context.Context {
+	return context.WithValue(ctx, injectorContextKey{}, inj)
+}
+
+// FromContext returns the [Injector] stored in a [context.Context] by [NewContext].
+func FromContext(ctx context.Context) (*Injector, bool) {
+	inj, ok := ctx.Value(injectorContextKey{}).(*Injector)
+	return inj, ok && inj != nil
+}
+
+// InjectContext returns the error of the injection point from the [Injector] of the [context.Context] (see [NewContext]), or from the [Default] [Injector].
+//
+// It returns nil if there is no [Injector].
+func InjectContext(ctx context.Context, name string) error {
+	inj, ok := FromContext(ctx)
+	if !ok {
+		inj = Default.Load()
+		if inj == nil {
+			return nil
+		}
+	}
+	return inj.inject(name, 1)
+}
+
+// Fault configures the error returned by an injection point.
+type Fault struct {
+	// Err is the returned error.
+	// If nil, [ErrInjected] is used.
+	//
+	// It is wrapped with a tag containing the name of the injection point (see [TagKey]) and a stack.
+	Err error
+	// Temporary marks the error as temporary or not (see [errtmp.Wrap]).
+	// If nil, the error is not marked.
+	Temporary *bool
+	// Tags are added to the error (see [errtag.Wrap]), in the order of the keys.
+	Tags map[string]string
+	// Probability is the probability (between 0 and 1) that the error is returned by a call.
+	// If 0, the error is always returned.
+	Probability float64
+	// Nth only returns the error on the nth call (1-based) of the injection point.
+	// If 0, the error is returned on all calls.
+	Nth int
+	// Times is the maximum number of times the error is returned.
+	// If 0, there is no limit.
+	Times int
+}
+
+// Injector manages the [Fault] of injection points.
+//
+// The zero value is ready to use.
+// It is safe for concurrent use.
+type Injector struct {
+	mu     sync.Mutex
+	points map[string]*point
+	rand   *rand.Rand
+}
+
+type point struct {
+	fault     *Fault
+	calls     int
+	triggered int
+}
+
+// Set sets the [Fault] of the injection point, and resets its counters.
+func (inj *Injector) Set(name string, f Fault) {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+	if inj.points == nil {
+		inj.points = make(map[string]*point)
+	}
+	inj.points[name] = &point{
+		fault: &f,
+	}
+}
+
+// Delete removes the [Fault] of the injection point.
+//
+// The counters are kept.
+func (inj *Injector) Delete(name string) {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+	p, ok := inj.points[name]
+	if ok {
+		p.fault = nil
+	}
+}
+
+// Reset removes all the faults and counters.
+func (inj *Injector) Reset() {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+	clear(inj.points)
+}
+
+// Seed makes the [Fault.Probability] deterministic, by using a pseudo-random generator with the given seed.
+func (inj *Injector) Seed(seed uint64) {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+	inj.rand = rand.New(rand.NewPCG(seed, seed)) //nolint:gosec // Fault injection doesn't need a secure generator.
+}
+
+// Calls returns the number of calls of the injection point.
+func (inj *Injector) Calls(name string) int {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+	p, ok := inj.points[name]
+	if !ok {
+		return 0
+	}
+	return p.calls
+}
+
+// Triggered returns the number of errors returned by the injection point.
+func (inj *Injector) Triggered(name string) int {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+	p, ok := inj.points[name]
+	if !ok {
+		return 0
+	}
+	return p.triggered
+}
+
+// Inject returns the error of the injection point.
+//
+// It returns nil if the injection point has no [Fault], or if the [Fault] is not triggered by this call.
+func (inj *Injector) Inject(name string) error {
+	return inj.inject(name, 1)
+}
+
+func (inj *Injector) inject(name string, skip int) error {
+	f, ok := inj.trigger(name)
+	if !ok {
+		return nil
+	}
+	return newError(name, f, skip+1)
+}
+
+func (inj *Injector) trigger(name string) (*Fault, bool) {
+	inj.mu.Lock()
+	defer inj.mu.Unlock()
+	if inj.points == nil {
+		inj.points = make(map[string]*point)
+	}
+	p, ok := inj.points[name]
+	if !ok {
+		p = new(point)
+		inj.points[name] = p
+	}
+	p.calls++
+	f := p.fault
+	if f == nil {
+		return nil, false
+	}
+	if f.Nth > 0 && p.calls != f.Nth {
+		return nil, false
+	}
+	if f.Times > 0 && p.triggered >= f.Times {
+		return nil, false
+	}
+	if f.Probability > 0 && inj.float64() >= f.Probability {
+		return nil, false
+	}
+	p.triggered++
+	return f, true
+}
+
+func (inj *Injector) float64() float64 {
+	if inj.rand != nil {
+		return inj.rand.Float64()
+	}
+	return rand.Float64() //nolint:gosec // Fault injection doesn't need a secure generator.
+}
+
+func newError(name string, f *Fault, skip int) error {
+	err := f.Err
+	if err == nil {
+		err = ErrInjected
+	}
+	for _, k := range slices.Sorted(maps.Keys(f.Tags)) {
+		err = errtag.Wrap(err, k, f.Tags[k])
+	}
+	err = errtag.Wrap(err, TagKey, name)
+	if f.Temporary != nil {
+		err = errtmp.Wrap(err, *f.Temporary)
+	}
+	return errstack.WrapSkip(err, skip+1)
+}
@@ -0,0 +1,176 @@
+package errfault_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pierrre/assert"
+	"github.com/pierrre/errors/errbase"
+	. "github.com/pierrre/errors/errfault"
+	"github.com/pierrre/errors/errstack"
+	"github.com/pierrre/errors/errtag"
+	"github.com/pierrre/errors/errtmp"
+)
+
+func Example() {
+	inj := new(Injector)
+	inj.Set("db.query", Fault{
+		Temporary: new(true),
+		Nth:       2,
+	})
+	for range 3 {
+		err := inj.Inject("db.query")
+		fmt.Println(err, errtmp.Is(err))
+	}
+	// Output:
+	// <nil> true
+	// injected fault true
+	// <nil> true
+}
+
+func TestInjectDisabled(t *testing.T) {
+	err := Inject("test")
+	assert.NoError(t, err)
+	err = InjectContext(t.Context(), "test")
+	assert.NoError(t, err)
+}
+
+func TestEnable(t *testing.T) {
+	inj := Enable(t)
+	assert.Equal(t, Default.Load(), inj)
+	err := Inject("test")
+	assert.NoError(t, err)
+	inj.Set("test", Fault{})
+	err = Inject("test")
+	assert.ErrorIs(t, err, ErrInjected)
+	assert.Equal(t, errtag.Get(err)[TagKey], "test")
+	err = InjectContext(t.Context(), "test")
+	assert.ErrorIs(t, err, ErrInjected)
+	assert.Equal(t, inj.Calls("test"), 2)
+	assert.Equal(t, inj.Triggered("test"), 2)
+}
+
+func TestEnableRestore(t *testing.T) {
+	t.Run("Sub", func(t *testing.T) {
+		Enable(t)
+	})
+	assert.Zero(t, Default.Load())
+}
+
+func TestContext(t *testing.T) {
+	inj := new(Injector)
+	ctx := NewContext(t.Context(), inj)
+	inj2, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, inj2, inj)
+	inj.Set("test", Fault{})
+	err := InjectContext(ctx, "test")
+	assert.ErrorIs(t, err, ErrInjected)
+	_, ok = FromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestFault(t *testing.T) {
+	errTest := errbase.New("test")
+	inj := new(Injector)
+	inj.Set("test", Fault{
+		Err:       errTest,
+		Temporary: new(false),
+		Tags: map[string]string{
+			"b": "2",
+			"a": "1",
+		},
+	})
+	err := inj.Inject("test")
+	assert.ErrorIs(t, err, errTest)
+	assert.False(t, errtmp.Is(err))
+	assert.MapEqual(t, errtag.Get(err), map[string]string{
+		"a":    "1",
+		"b":    "2",
+		TagKey: "test",
+	})
+	var found bool
+	for fs := range errstack.Frames(err) {
+		for f := range fs {
+			assert.Equal(t, f.Function, "github.com/pierrre/errors/errfault_test.TestFault")
+			found = true
+			break
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestFaultNth(t *testing.T) {
+	inj := new(Injector)
+	inj.Set("test", Fault{
+		Nth: 3,
+	})
+	var triggered []int
+	for i := range 5 {
+		if inj.Inject("test") != nil {
+			triggered = append(triggered, i+1)
+		}
+	}
+	assert.SliceEqual(t, triggered, []int{3})
+}
+
+func TestFaultTimes(t *testing.T) {
+	inj := new(Injector)
+	inj.Set("test", Fault{
+		Times: 2,
+	})
+	var count int
+	for range 5 {
+		if inj.Inject("test") != nil {
+			count++
+		}
+	}
+	assert.Equal(t, count, 2)
+	assert.Equal(t, inj.Triggered("test"), 2)
+}
+
+func TestFaultProbability(t *testing.T) {
+	run := func() []bool {
+		inj := new(Injector)
+		inj.Seed(42)
+		inj.Set("test", Fault{
+			Probability: 0.5,
+		})
+		res := make([]bool, 100)
+		for i := range res {
+			res[i] = inj.Inject("test") != nil
+		}
+		return res
+	}
+	res := run()
+	assert.SliceEqual(t, run(), res)
+	var count int
+	for _, v := range res {
+		if v {
+			count++
+		}
+	}
+	assert.Greater(t, count, 20)
+	assert.Less(t, count, 80)
+}
+
+func TestDeleteReset(t *testing.T) {
+	inj := new(Injector)
+	inj.Set("test", Fault{})
+	assert.Error(t, inj.Inject("test"))
+	inj.Delete("test")
+	assert.NoError(t, inj.Inject("test"))
+	assert.Equal(t, inj.Calls("test"), 2)
+	inj.Delete("unknown")
+	inj.Reset()
+	assert.Equal(t, inj.Calls("test"), 0)
+	assert.Equal(t, inj.Triggered("test"), 0)
+	assert.NoError(t, inj.Inject("test"))
+}
+
+func BenchmarkInjectDisabled(b *testing.B) {
+	for b.Loop() {
+		_ = Inject("test")
+	}
+}
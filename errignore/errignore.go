@@ -2,8 +2,11 @@
 package errignore
 
 import (
-	"github.com/pierrre/errors"
+	"fmt"
+
 	"github.com/pierrre/errors/errappend"
+	"github.com/pierrre/errors/erriter"
+	"github.com/pierrre/errors/errverbose"
 )
 
 // Wrap marks an error as ignored.
@@ -26,6 +29,10 @@ func (err *ignore) Unwrap() error {
 	return err.error
 }
 
+func (err *ignore) Format(s fmt.State, verb rune) {
+	errverbose.Format(s, verb, err)
+}
+
 func (err *ignore) ErrorAppend(b []byte) []byte {
 	return errappend.Append(b, err.error)
 }
@@ -42,10 +49,7 @@ func (err *ignore) Ignored() bool {
 //
 // By default, an error is not ignored.
 func Is(err error) bool {
-	var werr interface {
-		Ignored() bool
-	}
-	ok := errors.As(err, &werr)
+	werr, ok := erriter.Find[interface{ Ignored() bool }](err)
 	if ok {
 		return werr.Ignored()
 	}
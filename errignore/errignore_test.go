@@ -85,7 +85,7 @@ func TestIsAllocs(t *testing.T) {
 	var res bool
 	assert.AllocsPerRun(t, 100, func() {
 		res = Is(err)
-	}, 1)
+	}, 0)
 	testSink = res
 }
 
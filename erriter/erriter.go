@@ -3,27 +3,268 @@ package erriter
 
 import (
 	"iter"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/pierrre/go-libs/syncutil"
+	"github.com/pierrre/go-libs/syncutil/atomicutil"
 )
 
 // All returns an [iter.Seq] that iterates over an error tree recursively.
+//
+// The errors that are part of a cycle or that exceed the limits (see [DefaultLimits]) are not visited (see [Walk]).
 func All(err error) iter.Seq[error] {
 	return func(yield func(error) bool) {
-		iterFunc(err, func(err error) bool {
-			return yield(err)
+		Walk(err, func(n Node) WalkAction {
+			if n.Cycle || n.Truncated {
+				return WalkSkip
+			}
+			if !yield(n.Err) {
+				return WalkStop
+			}
+			return WalkContinue
 		})
 	}
 }
 
-func iterFunc(err error, f func(err error) bool) bool {
-	for err != nil {
-		ok := f(err)
-		if !ok {
+// Order is the order used to iterate over an error tree.
+type Order int
+
+// Orders.
+const (
+	// OrderDepthFirst iterates depth-first, from the outermost error to the innermost (pre-order).
+	// It is the order of [All].
+	OrderDepthFirst Order = iota
+	// OrderBreadthFirst iterates level by level, from the outermost errors to the innermost.
+	OrderBreadthFirst
+	// OrderInnermostFirst iterates depth-first, but yields the wrapped errors before the error that wraps them (post-order).
+	// The first error is the innermost error of the first branch.
+	OrderInnermostFirst
+)
+
+// AllOrder is like [All], but iterates in the given [Order].
+//
+// For an order other than [OrderDepthFirst], the errors are collected before being yielded.
+// It panics during the iteration if the order is invalid.
+func AllOrder(err error, order Order) iter.Seq[error] {
+	return func(yield func(error) bool) {
+		switch order {
+		case OrderDepthFirst:
+			for err := range All(err) {
+				if !yield(err) {
+					return
+				}
+			}
+		case OrderBreadthFirst:
+			allBreadthFirst(err, yield)
+		case OrderInnermostFirst:
+			allInnermostFirst(err, yield)
+		default:
+			panic("invalid order: " + strconv.Itoa(int(order)))
+		}
+	}
+}
+
+type levelError struct {
+	err   error
+	level int
+}
+
+func collectLevels(err error) []levelError {
+	var les []levelError
+	Walk(err, func(n Node) WalkAction {
+		if n.Cycle || n.Truncated {
+			return WalkSkip
+		}
+		les = append(les, levelError{
+			err:   n.Err,
+			level: n.Level,
+		})
+		return WalkContinue
+	})
+	return les
+}
+
+func allBreadthFirst(err error, yield func(error) bool) {
+	les := collectLevels(err)
+	slices.SortStableFunc(les, func(a, b levelError) int {
+		return a.level - b.level
+	})
+	for _, le := range les {
+		if !yield(le.err) {
+			return
+		}
+	}
+}
+
+func allInnermostFirst(err error, yield func(error) bool) {
+	les := collectLevels(err)
+	// The errors are in pre-order.
+	// An error is yielded when all its descendants have been yielded, i.e. when the next error has a lower or equal level.
+	stack := make([]levelError, 0, len(les))
+	for _, le := range les {
+		for len(stack) > 0 && stack[len(stack)-1].level >= le.level {
+			if !yield(stack[len(stack)-1].err) {
+				return
+			}
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, le)
+	}
+	for _, le := range slices.Backward(stack) {
+		if !yield(le.err) {
+			return
+		}
+	}
+}
+
+// Limits are the limits applied when an error tree is traversed.
+//
+// They protect against buggy custom errors or very large error trees.
+type Limits struct {
+	// MaxDepth is the maximum number of ancestors of a visited error.
+	// 0 means no limit.
+	MaxDepth int
+	// MaxNodes is the maximum number of visited errors.
+	// 0 means no limit.
+	MaxNodes int
+}
+
+// DefaultLimits are the [Limits] used by [Walk], and all the functions of this module that traverse an error tree.
+//
+// The default value is a MaxDepth of 1000 and a MaxNodes of 100000.
+var DefaultLimits atomicutil.Value[Limits]
+
+func init() {
+	DefaultLimits.Store(Limits{
+		MaxDepth: 1000,
+		MaxNodes: 100000,
+	})
+}
+
+// Node is an error in an error tree, with its position.
+//
+// It is provided by [Walk].
+type Node struct {
+	// Err is the error.
+	Err error
+	// Parent is the error that wraps Err, or nil for the root.
+	Parent error
+	// Path contains the indexes of the joined errors (`Unwrap() []error`) from the root to Err.
+	// It is empty for the main chain.
+	//
+	// It is reused during the walk, and must be copied in order to be retained.
+	Path []int
+	// Depth is the position of Err in its wrap chain (`Unwrap() error`).
+	// It is 0 for the root and for the errors returned by `Unwrap() []error`.
+	Depth int
+	// Level is the number of ancestors of Err.
+	// It is 0 for the root.
+	Level int
+	// Cycle is true if Err is one of its ancestors (compared by identity).
+	// The errors wrapped by Err are not visited.
+	Cycle bool
+	// Truncated is true if Err exceeds the limits (see [DefaultLimits]).
+	// If the maximum depth is reached, the errors wrapped by Err are not visited.
+	// If the maximum number of errors is reached, the walk stops after this error.
+	Truncated bool
+}
+
+// WalkAction is the action returned by the function called by [Walk].
+type WalkAction int
+
+// Walk actions.
+const (
+	// WalkContinue continues the walk.
+	WalkContinue WalkAction = iota
+	// WalkSkip skips the errors wrapped by the current error.
+	WalkSkip
+	// WalkStop stops the walk.
+	WalkStop
+)
+
+type walker struct {
+	limits    Limits
+	count     int
+	path      []int
+	ancestors []error
+}
+
+var walkerPool = syncutil.Pool[*walker]{
+	New: func() *walker {
+		return &walker{
+			path:      make([]int, 0, 16),
+			ancestors: make([]error, 0, 64),
+		}
+	},
+}
+
+// Walk walks an error tree recursively, in the same order as [All], and calls f for each error.
+//
+// The returned [WalkAction] allows to skip the errors wrapped by the current error, or to stop the walk.
+//
+// It detects cycles and applies the limits (see [DefaultLimits]), see [Node.Cycle] and [Node.Truncated].
+func Walk(err error, f func(n Node) WalkAction) {
+	if err == nil {
+		return
+	}
+	w := walkerPool.Get()
+	defer func() {
+		clear(w.ancestors[:cap(w.ancestors)])
+		*w = walker{
+			path:      w.path[:0],
+			ancestors: w.ancestors[:0],
+		}
+		walkerPool.Put(w)
+	}()
+	w.limits = DefaultLimits.Load()
+	w.walk(err, nil, f)
+}
+
+// walk walks a wrap chain.
+//
+// f is not stored in the walker, in order to not escape to the heap.
+func (w *walker) walk(err error, parent error, f func(n Node) WalkAction) bool {
+	ancestorsLen := len(w.ancestors)
+	defer func() {
+		clear(w.ancestors[ancestorsLen:])
+		w.ancestors = w.ancestors[:ancestorsLen]
+	}()
+	for depth := 0; err != nil; depth++ {
+		n := Node{
+			Err:    err,
+			Parent: parent,
+			Path:   w.path,
+			Depth:  depth,
+			Level:  len(w.ancestors),
+		}
+		stop := false
+		switch {
+		case isAncestor(err, w.ancestors):
+			n.Cycle = true
+		case w.limits.MaxNodes > 0 && w.count >= w.limits.MaxNodes:
+			n.Truncated = true
+			stop = true
+		case w.limits.MaxDepth > 0 && len(w.ancestors) >= w.limits.MaxDepth:
+			n.Truncated = true
+		}
+		w.count++
+		action := f(n)
+		if stop || action == WalkStop {
 			return false
 		}
+		if action == WalkSkip || n.Cycle || n.Truncated {
+			return true
+		}
+		w.ancestors = append(w.ancestors, err)
 		var errs []error
+		parent = err
 		errs, err = Unwrap(err)
-		for _, err := range errs {
-			ok := iterFunc(err, f)
+		for i, e := range errs {
+			w.path = append(w.path, i)
+			ok := w.walk(e, parent, f)
+			w.path = w.path[:len(w.path)-1]
 			if !ok {
 				return false
 			}
@@ -32,6 +273,152 @@ func iterFunc(err error, f func(err error) bool) bool {
 	return true
 }
 
+// isAncestor returns true if err is one of the ancestors.
+//
+// Non-comparable errors are never considered as ancestors.
+func isAncestor(err error, ancestors []error) bool {
+	if len(ancestors) == 0 || !isComparable(err) {
+		return false
+	}
+	for _, a := range ancestors {
+		if a == err {
+			return true
+		}
+	}
+	return false
+}
+
+// isComparable returns true if the error can be compared with ==.
+//
+// It checks the value and not only the type, because a comparable struct or array type can contain a non-comparable value in an interface field.
+func isComparable(err error) bool {
+	typ := reflect.TypeOf(err)
+	switch typ.Kind() { //nolint:exhaustive // Other kinds are checked below.
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		return true
+	}
+	return typ.Comparable() && reflect.ValueOf(err).Comparable()
+}
+
+// Is is like [errors.Is], but it traverses the error tree with [All], which detects cycles and applies the limits.
+func Is(err, target error) bool {
+	if err == nil || target == nil {
+		return err == target //nolint:errorlint // We want to compare the errors.
+	}
+	comparable := isComparable(target)
+	for e := range All(err) {
+		if comparable && e == target { //nolint:errorlint // We want to compare the errors.
+			return true
+		}
+		if x, ok := e.(interface{ Is(error) bool }); ok && x.Is(target) { //nolint:errorlint // We want to check the current error.
+			return true
+		}
+	}
+	return false
+}
+
+// AsType is like [errors.AsType], but it traverses the error tree with [All], which detects cycles and applies the limits.
+//
+// See [Find].
+func AsType[E error](err error) (E, bool) {
+	return Find[E](err)
+}
+
+// Find returns the first error in the error tree that matches T.
+//
+// It follows the same semantics as [errors.As]: an error matches if it is assignable to T, or if it has an `As(any) bool` method that returns true for a *T.
+// Contrary to [errors.As], T can be any type, such as an interface that doesn't implement error.
+func Find[T any](err error) (T, bool) {
+	for e := range All(err) {
+		v, ok := match[T](e)
+		if ok {
+			return v, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// FindAll returns an [iter.Seq] of all the errors in the error tree that match T, including the joined errors.
+//
+// See [Find].
+func FindAll[T any](err error) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := range All(err) {
+			v, ok := match[T](e)
+			if ok && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func match[T any](err error) (T, bool) {
+	v, ok := any(err).(T)
+	if ok {
+		return v, true
+	}
+	x, ok := err.(interface{ As(any) bool }) //nolint:errorlint // We want to check the current error.
+	if ok {
+		var t T // Declared here, because it escapes to the heap.
+		if x.As(&t) {
+			return t, true
+		}
+	}
+	return v, false
+}
+
+// FindFunc returns the first error in the error tree for which f returns true.
+func FindFunc(err error, f func(error) bool) (error, bool) {
+	for e := range All(err) {
+		if f(e) {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+// Cause returns the innermost error of the main chain (`Unwrap() error`).
+//
+// If the main chain ends with joined errors (`Unwrap() []error`), it returns the error that joins them.
+// It returns nil if err is nil.
+func Cause(err error) error {
+	var cause error
+	Walk(err, func(n Node) WalkAction {
+		if len(n.Path) > 0 {
+			return WalkStop
+		}
+		if !n.Cycle && !n.Truncated {
+			cause = n.Err
+		}
+		return WalkContinue
+	})
+	return cause
+}
+
+// Leaves returns an [iter.Seq] of the errors in the error tree that don't wrap any error.
+//
+// They are the innermost errors (the causes) of each branch of the error tree.
+func Leaves(err error) iter.Seq[error] {
+	return func(yield func(error) bool) {
+		for e := range All(err) {
+			errs, next := Unwrap(e)
+			if len(errs) == 0 && next == nil && !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// Count returns the number of errors in the error tree.
+func Count(err error) int {
+	n := 0
+	for range All(err) {
+		n++
+	}
+	return n
+}
+
 // Unwrap unwraps an error.
 //
 // If the error implements `Unwrap() error`, it returns the unwrapped error.
@@ -1,6 +1,10 @@
 package erriter_test
 
 import (
+	std_errors "errors"
+	"fmt"
+	"io/fs"
+	"slices"
 	"testing"
 
 	"github.com/pierrre/assert"
@@ -47,6 +51,582 @@ func TestAllAllocs(t *testing.T) {
 	}, 0)
 }
 
+func newTestOrderError() (err, errA, errAInner, errB error) {
+	errAInner = errbase.New("a")
+	errA = errmsg.Wrap(errAInner, "wrap")
+	errB = errbase.New("b")
+	err = errmsg.Wrap(errors.Join(errA, errB), "test")
+	return err, errA, errAInner, errB
+}
+
+func TestAllOrder(t *testing.T) {
+	err, errA, errAInner, errB := newTestOrderError()
+	errJoin := errors.Unwrap(err)
+	errJoinInner := errors.Unwrap(errJoin)
+	for _, tc := range []struct {
+		name     string
+		order    Order
+		expected []error
+	}{
+		{
+			name:     "DepthFirst",
+			order:    OrderDepthFirst,
+			expected: []error{err, errJoin, errJoinInner, errA, errAInner, errB},
+		},
+		{
+			name:     "BreadthFirst",
+			order:    OrderBreadthFirst,
+			expected: []error{err, errJoin, errJoinInner, errA, errB, errAInner},
+		},
+		{
+			name:     "InnermostFirst",
+			order:    OrderInnermostFirst,
+			expected: []error{errAInner, errA, errB, errJoinInner, errJoin, err},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := slices.Collect(AllOrder(err, tc.order))
+			assert.SliceEqual(t, errs, tc.expected)
+		})
+	}
+}
+
+func TestAllOrderStop(t *testing.T) {
+	err, _, _, _ := newTestOrderError()
+	for _, order := range []Order{OrderDepthFirst, OrderBreadthFirst, OrderInnermostFirst} {
+		count := 0
+		for range AllOrder(err, order) {
+			count++
+			if count == 2 {
+				break
+			}
+		}
+		assert.Equal(t, count, 2)
+	}
+}
+
+func TestAllOrderCycle(t *testing.T) {
+	errCycle := &testCycleError{msg: "cycle"}
+	err := errmsg.Wrap(errCycle, "test")
+	errs := slices.Collect(AllOrder(err, OrderInnermostFirst))
+	assert.SliceEqual(t, errs, []error{errCycle, err})
+}
+
+func TestAllOrderInvalid(t *testing.T) {
+	err := newTestError()
+	assert.Panics(t, func() {
+		for range AllOrder(err, Order(-1)) {
+		}
+	})
+}
+
+func TestAllOrderDepthFirstAllocs(t *testing.T) {
+	err := newTestError()
+	assert.AllocsPerRun(t, 100, func() {
+		for range AllOrder(err, OrderDepthFirst) {
+		}
+	}, 0)
+}
+
+func ExampleWalk() {
+	err := errors.Join(
+		errmsg.Wrap(errbase.New("a"), "wrap"),
+		errbase.New("b"),
+	)
+	err = errmsg.Wrap(err, "test")
+	Walk(err, func(n Node) WalkAction {
+		fmt.Printf("%v %d %q\n", n.Path, n.Depth, n.Err)
+		return WalkContinue
+	})
+	// Output:
+	// [] 0 "test: wrap: a\nb"
+	// [] 1 "wrap: a\nb"
+	// [] 2 "wrap: a\nb"
+	// [0] 0 "wrap: a"
+	// [0] 1 "a"
+	// [1] 0 "b"
+}
+
+type testNode struct {
+	err       error
+	parent    error
+	path      []int
+	depth     int
+	cycle     bool
+	truncated bool
+}
+
+func collectWalk(err error, f func(n Node) WalkAction) []testNode {
+	var nodes []testNode
+	Walk(err, func(n Node) WalkAction {
+		nodes = append(nodes, testNode{
+			err:       n.Err,
+			parent:    n.Parent,
+			path:      slices.Clone(n.Path),
+			depth:     n.Depth,
+			cycle:     n.Cycle,
+			truncated: n.Truncated,
+		})
+		return f(n)
+	})
+	return nodes
+}
+
+func TestWalk(t *testing.T) {
+	errBase := errbase.New("error")
+	errJoin := errors.Join(errBase, errBase)
+	errJoinInner := errors.Unwrap(errJoin)
+	err := errmsg.Wrap(errJoin, "test")
+	nodes := collectWalk(err, func(n Node) WalkAction {
+		return WalkContinue
+	})
+	assert.DeepEqual(t, nodes, []testNode{
+		{err: err, path: []int{}, depth: 0},
+		{err: errJoin, parent: err, path: []int{}, depth: 1},
+		{err: errJoinInner, parent: errJoin, path: []int{}, depth: 2},
+		{err: errBase, parent: errJoinInner, path: []int{0}, depth: 0},
+		{err: errBase, parent: errJoinInner, path: []int{1}, depth: 0},
+	})
+}
+
+func TestWalkLevel(t *testing.T) {
+	err, _, _, _ := newTestOrderError()
+	var levels []int
+	Walk(err, func(n Node) WalkAction {
+		levels = append(levels, n.Level)
+		return WalkContinue
+	})
+	assert.SliceEqual(t, levels, []int{0, 1, 2, 3, 4, 3})
+}
+
+func TestWalkSkip(t *testing.T) {
+	err := newTestError()
+	nodes := collectWalk(err, func(n Node) WalkAction {
+		if n.Depth == 1 {
+			return WalkSkip
+		}
+		return WalkContinue
+	})
+	assert.SliceLen(t, nodes, 2)
+}
+
+func TestWalkSkipJoin(t *testing.T) {
+	err := newTestError()
+	nodes := collectWalk(err, func(n Node) WalkAction {
+		if len(n.Path) > 0 && n.Path[0] == 0 {
+			return WalkSkip
+		}
+		return WalkContinue
+	})
+	assert.SliceLen(t, nodes, 5)
+}
+
+func TestWalkStop(t *testing.T) {
+	err := newTestError()
+	nodes := collectWalk(err, func(n Node) WalkAction {
+		if len(n.Path) > 0 {
+			return WalkStop
+		}
+		return WalkContinue
+	})
+	assert.SliceLen(t, nodes, 4)
+}
+
+func TestWalkNil(t *testing.T) {
+	nodes := collectWalk(nil, func(n Node) WalkAction {
+		return WalkContinue
+	})
+	assert.SliceEmpty(t, nodes)
+}
+
+func TestWalkAllocs(t *testing.T) {
+	err := newTestError()
+	assert.AllocsPerRun(t, 100, func() {
+		Walk(err, func(n Node) WalkAction {
+			return WalkContinue
+		})
+	}, 0)
+}
+
+func BenchmarkWalk(b *testing.B) {
+	err := newTestError()
+	for b.Loop() {
+		Walk(err, func(n Node) WalkAction {
+			return WalkContinue
+		})
+	}
+}
+
+type testCycleError struct {
+	msg string
+}
+
+func (e *testCycleError) Error() string {
+	return e.msg
+}
+
+func (e *testCycleError) Unwrap() error {
+	return e
+}
+
+func TestWalkCycle(t *testing.T) {
+	errCycle := &testCycleError{msg: "cycle"}
+	err := errmsg.Wrap(errCycle, "test")
+	nodes := collectWalk(err, func(n Node) WalkAction {
+		return WalkContinue
+	})
+	assert.SliceLen(t, nodes, 3)
+	assert.False(t, nodes[1].cycle)
+	assert.True(t, nodes[2].cycle)
+}
+
+func TestWalkCycleJoin(t *testing.T) {
+	errJoin := &testJoinCycleError{}
+	errJoin.errs = []error{errbase.New("error"), errJoin}
+	nodes := collectWalk(errJoin, func(n Node) WalkAction {
+		return WalkContinue
+	})
+	assert.SliceLen(t, nodes, 3)
+	assert.True(t, nodes[2].cycle)
+	assert.SliceEqual(t, nodes[2].path, []int{1})
+}
+
+type testJoinCycleError struct {
+	errs []error
+}
+
+func (e *testJoinCycleError) Error() string {
+	return "join cycle"
+}
+
+func (e *testJoinCycleError) Unwrap() []error {
+	return e.errs
+}
+
+type testValueError struct {
+	errs []error
+}
+
+func (e testValueError) Error() string {
+	return "value"
+}
+
+func (e testValueError) Unwrap() []error {
+	return e.errs
+}
+
+func TestWalkNotComparable(t *testing.T) {
+	err := testValueError{errs: []error{errbase.New("error")}}
+	nodes := collectWalk(err, func(n Node) WalkAction {
+		return WalkContinue
+	})
+	assert.SliceLen(t, nodes, 2)
+}
+
+type testWrapValueError struct {
+	err error
+}
+
+func (e testWrapValueError) Error() string {
+	return "wrap: " + e.err.Error()
+}
+
+func (e testWrapValueError) Unwrap() error {
+	return e.err
+}
+
+func newTestNotComparableNestedError() error {
+	return testWrapValueError{err: testValueError{errs: []error{testWrapValueError{err: testValueError{errs: []error{errbase.New("x")}}}}}}
+}
+
+func TestWalkNotComparableNested(t *testing.T) {
+	err := newTestNotComparableNestedError()
+	nodes := collectWalk(err, func(n Node) WalkAction {
+		return WalkContinue
+	})
+	assert.SliceLen(t, nodes, 5)
+}
+
+func setTestLimits(tb testing.TB, limits Limits) {
+	tb.Helper()
+	previous := DefaultLimits.Swap(limits)
+	tb.Cleanup(func() {
+		DefaultLimits.Store(previous)
+	})
+}
+
+func TestWalkMaxDepth(t *testing.T) {
+	setTestLimits(t, Limits{MaxDepth: 2})
+	err := newTestError()
+	nodes := collectWalk(err, func(n Node) WalkAction {
+		return WalkContinue
+	})
+	assert.SliceLen(t, nodes, 3)
+	assert.True(t, nodes[2].truncated)
+}
+
+func TestWalkMaxNodes(t *testing.T) {
+	setTestLimits(t, Limits{MaxNodes: 3})
+	err := newTestError()
+	nodes := collectWalk(err, func(n Node) WalkAction {
+		return WalkContinue
+	})
+	assert.SliceLen(t, nodes, 4)
+	assert.True(t, nodes[3].truncated)
+}
+
+func TestAllCycle(t *testing.T) {
+	errCycle := &testCycleError{msg: "cycle"}
+	err := errmsg.Wrap(errCycle, "test")
+	count := 0
+	for range All(err) {
+		count++
+	}
+	assert.Equal(t, count, 2)
+}
+
+func TestIs(t *testing.T) {
+	errBase := errbase.New("error")
+	err := newTestErrorWith(errBase)
+	assert.True(t, Is(err, errBase))
+	assert.False(t, Is(err, errbase.New("other")))
+}
+
+func TestIsNil(t *testing.T) {
+	assert.True(t, Is(nil, nil))
+	assert.False(t, Is(nil, errbase.New("error")))
+	assert.False(t, Is(errbase.New("error"), nil))
+}
+
+func TestIsMethod(t *testing.T) {
+	err := errmsg.Wrap(&testIsAsError{}, "test")
+	assert.True(t, Is(err, fs.ErrNotExist))
+}
+
+func TestIsCycle(t *testing.T) {
+	err := &testCycleError{msg: "cycle"}
+	assert.False(t, Is(err, errbase.New("error")))
+}
+
+func TestIsNotComparable(t *testing.T) {
+	err := errmsg.Wrap(testValueError{}, "test")
+	assert.False(t, Is(err, testValueError{}))
+}
+
+func TestIsNotComparableNested(t *testing.T) {
+	err := newTestNotComparableNestedError()
+	assert.False(t, Is(err, testWrapValueError{err: testValueError{}}))
+	assert.False(t, Is(err, errbase.New("x")))
+}
+
+func TestAsType(t *testing.T) {
+	errPath := &fs.PathError{Op: "op", Path: "path", Err: errbase.New("error")}
+	err := errmsg.Wrap(errPath, "test")
+	v, ok := AsType[*fs.PathError](err)
+	assert.True(t, ok)
+	assert.Equal(t, v, errPath)
+}
+
+func TestAsTypeMethod(t *testing.T) {
+	err := errmsg.Wrap(&testIsAsError{}, "test")
+	v, ok := AsType[*fs.PathError](err)
+	assert.True(t, ok)
+	assert.Equal(t, v.Op, "as")
+}
+
+func TestAsTypeNotFound(t *testing.T) {
+	err := newTestError()
+	_, ok := AsType[*fs.PathError](err)
+	assert.False(t, ok)
+}
+
+func TestAsTypeCycle(t *testing.T) {
+	err := &testCycleError{msg: "cycle"}
+	_, ok := AsType[*fs.PathError](err)
+	assert.False(t, ok)
+}
+
+type testIsAsError struct{}
+
+func (e *testIsAsError) Error() string {
+	return "is as"
+}
+
+func (e *testIsAsError) Is(target error) bool {
+	return target == fs.ErrNotExist //nolint:errorlint // We want to compare the errors.
+}
+
+func (e *testIsAsError) As(target any) bool {
+	p, ok := target.(**fs.PathError)
+	if ok {
+		*p = &fs.PathError{Op: "as"}
+	}
+	return ok
+}
+
+func newTestErrorWith(err error) error {
+	err = errors.Join(err, errbase.New("other"))
+	err = errmsg.Wrap(err, "test")
+	return err
+}
+
+func TestIsAllocs(t *testing.T) {
+	errBase := errbase.New("error")
+	err := newTestErrorWith(errBase)
+	assert.AllocsPerRun(t, 100, func() {
+		_ = Is(err, errBase)
+	}, 0)
+}
+
+func BenchmarkIs(b *testing.B) {
+	errBase := errbase.New("error")
+	err := newTestErrorWith(errBase)
+	for b.Loop() {
+		_ = Is(err, errBase)
+	}
+}
+
+func ExampleFind() {
+	err := errbase.New("error")
+	err = &fs.PathError{Op: "open", Path: "file", Err: err}
+	err = errmsg.Wrap(err, "test")
+	pathErr, ok := Find[*fs.PathError](err)
+	fmt.Println(ok, pathErr.Op)
+	// Output: true open
+}
+
+func TestFind(t *testing.T) {
+	errPath := &fs.PathError{Op: "op", Path: "path", Err: errbase.New("error")}
+	err := errors.Join(errbase.New("other"), errmsg.Wrap(errPath, "test"))
+	v, ok := Find[*fs.PathError](err)
+	assert.True(t, ok)
+	assert.Equal(t, v, errPath)
+}
+
+func TestFindInterface(t *testing.T) {
+	err := errmsg.Wrap(&testIsAsError{}, "test")
+	v, ok := Find[interface{ As(any) bool }](err)
+	assert.True(t, ok)
+	assert.NotZero(t, v)
+}
+
+func TestFindNotFound(t *testing.T) {
+	err := newTestError()
+	v, ok := Find[*fs.PathError](err)
+	assert.False(t, ok)
+	assert.Zero(t, v)
+}
+
+func TestFindAllocs(t *testing.T) {
+	err := newTestError()
+	assert.AllocsPerRun(t, 100, func() {
+		_, _ = Find[*fs.PathError](err)
+	}, 0)
+}
+
+func BenchmarkFind(b *testing.B) {
+	err := newTestError()
+	for b.Loop() {
+		_, _ = Find[*fs.PathError](err)
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	errPath1 := &fs.PathError{Op: "op1", Err: errbase.New("error")}
+	errPath2 := &fs.PathError{Op: "op2", Err: errPath1}
+	errPath3 := &fs.PathError{Op: "op3", Err: errbase.New("error")}
+	err := errmsg.Wrap(errors.Join(errPath2, errPath3, &testIsAsError{}), "test")
+	var ops []string
+	for v := range FindAll[*fs.PathError](err) {
+		ops = append(ops, v.Op)
+	}
+	assert.SliceEqual(t, ops, []string{"op2", "op1", "op3", "as"})
+}
+
+func TestFindAllStop(t *testing.T) {
+	err := errors.Join(&fs.PathError{Op: "op1"}, &fs.PathError{Op: "op2"})
+	count := 0
+	for range FindAll[*fs.PathError](err) {
+		count++
+		break
+	}
+	assert.Equal(t, count, 1)
+}
+
+func TestFindFunc(t *testing.T) {
+	errBase := errbase.New("error")
+	err := newTestErrorWith(errBase)
+	v, ok := FindFunc(err, func(e error) bool {
+		return e.Error() == "error"
+	})
+	assert.True(t, ok)
+	assert.Equal(t, v, errBase)
+}
+
+func TestFindFuncNotFound(t *testing.T) {
+	err := newTestError()
+	v, ok := FindFunc(err, func(e error) bool {
+		return false
+	})
+	assert.False(t, ok)
+	assert.NoError(t, v)
+}
+
+func ExampleCause() {
+	err := errbase.New("error")
+	err = errmsg.Wrap(err, "a")
+	err = errmsg.Wrap(err, "b")
+	fmt.Println(Cause(err))
+	// Output: error
+}
+
+func TestCause(t *testing.T) {
+	errBase := errbase.New("error")
+	err := errmsg.Wrap(errmsg.Wrap(errBase, "a"), "b")
+	assert.Equal(t, Cause(err), errBase)
+}
+
+func TestCauseJoin(t *testing.T) {
+	errJoin := std_errors.Join(errbase.New("a"), errbase.New("b"))
+	err := errmsg.Wrap(errJoin, "test")
+	assert.Equal(t, Cause(err), errJoin)
+}
+
+func TestCauseCycle(t *testing.T) {
+	errCycle := &testCycleError{msg: "cycle"}
+	err := errmsg.Wrap(errCycle, "test")
+	assert.Equal(t, Cause(err), error(errCycle))
+}
+
+func TestCauseNil(t *testing.T) {
+	assert.NoError(t, Cause(nil))
+}
+
+func TestLeaves(t *testing.T) {
+	errA := errbase.New("a")
+	errB := errbase.New("b")
+	errC := errbase.New("c")
+	err := errmsg.Wrap(std_errors.Join(errmsg.Wrap(errA, "wrap"), std_errors.Join(errB, errC)), "test")
+	leaves := slices.Collect(Leaves(err))
+	assert.SliceEqual(t, leaves, []error{errA, errB, errC})
+}
+
+func TestLeavesStop(t *testing.T) {
+	err := std_errors.Join(errbase.New("a"), errbase.New("b"))
+	count := 0
+	for range Leaves(err) {
+		count++
+		break
+	}
+	assert.Equal(t, count, 1)
+}
+
+func TestCount(t *testing.T) {
+	err := newTestError()
+	assert.Equal(t, Count(err), 5)
+	assert.Equal(t, Count(nil), 0)
+}
+
 func TestFirstKeys(t *testing.T) {
 	seq := func(yield func(string, int) bool) {
 		yield("a", 1)
@@ -2,7 +2,10 @@
 package errjoin
 
 import (
+	"fmt"
+
 	"github.com/pierrre/errors/errappend"
+	"github.com/pierrre/errors/errverbose"
 )
 
 // Join returns an error that wraps the given errors.
@@ -58,6 +61,27 @@ func (e *joinError) ErrorAppend(b []byte) []byte {
 	return b
 }
 
+// ErrorAppendLimits appends the messages of the errors, limited by [errverbose.Limits.MaxJoinErrors].
+//
+// It is used by the verbose message, not by Error, which returns all the messages.
+func (e *joinError) ErrorAppendLimits(b []byte, limits errverbose.Limits) []byte {
+	for i, err := range e.errs {
+		if i != 0 {
+			b = append(b, '\n')
+		}
+		if limits.MaxJoinErrors > 0 && i >= limits.MaxJoinErrors {
+			b = errverbose.AppendMoreErrors(b, len(e.errs)-i)
+			break
+		}
+		b = errverbose.AppendMessageLimits(b, err, limits)
+	}
+	return b
+}
+
 func (e *joinError) Unwrap() []error {
 	return e.errs
 }
+
+func (e *joinError) Format(s fmt.State, verb rune) {
+	errverbose.Format(s, verb, e)
+}
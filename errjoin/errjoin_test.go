@@ -9,6 +9,7 @@ import (
 	"github.com/pierrre/assert"
 	"github.com/pierrre/errors/errbase"
 	. "github.com/pierrre/errors/errjoin"
+	"github.com/pierrre/errors/errverbose"
 )
 
 var testSink any
@@ -123,3 +124,15 @@ func BenchmarkErrorSingle(b *testing.B) {
 		_ = err.Error()
 	}
 }
+
+func TestMaxJoinErrors(t *testing.T) {
+	previous := errverbose.DefaultLimits.Swap(errverbose.Limits{MaxJoinErrors: 2})
+	defer errverbose.DefaultLimits.Store(previous)
+	err := Join(errbase.New("error 1"), errbase.New("error 2"), errbase.New("error 3"), errbase.New("error 4"))
+	assert.ErrorEqual(t, err, "error 1\nerror 2\nerror 3\nerror 4")
+	assert.StringHasPrefix(t, errverbose.String(err), "error 1\nerror 2\n... and 2 more errors\n")
+	err = Join(errbase.New("error 1"), errbase.New("error 2"), Join(errbase.New("error 3"), errbase.New("error 4"), errbase.New("error 5")))
+	assert.StringHasPrefix(t, errverbose.String(err), "error 1\nerror 2\n... and 1 more error\n")
+	err = Join(errbase.New("error 1"), Join(errbase.New("error 2"), errbase.New("error 3"), errbase.New("error 4")))
+	assert.StringHasPrefix(t, errverbose.String(err), "error 1\nerror 2\nerror 3\n... and 1 more error\n")
+}
@@ -0,0 +1,384 @@
+// Package errmetrics provides in-process counters of errors.
+//
+// The errors are counted by configurable dimensions (see [Options]).
+// A [Recorder] implements [expvar.Var] and [http.Handler] (Prometheus text format).
+// Call [Publish] to expose the [Default] [Recorder] with expvar, e.g. errmetrics.Publish("errors").
+// This package doesn't publish anything by itself, and doesn't register the handler.
+package errmetrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pierrre/errors/errstack"
+	"github.com/pierrre/errors/errtag"
+	"github.com/pierrre/errors/errtmp"
+	"github.com/pierrre/go-libs/bytesutil"
+	"github.com/pierrre/go-libs/syncutil/atomicutil"
+)
+
+// Default is the [Recorder] used by [Record].
+//
+// The default value counts the errors by temporariness and function.
+// Set it to nil to disable [Record].
+var Default atomicutil.Value[*Recorder]
+
+func init() {
+	Default.Store(NewRecorder(Options{
+		Temporary: true,
+		Function:  true,
+	}))
+}
+
+// Publish publishes the [Default] [Recorder] with [expvar.Publish] under the given name.
+//
+// The published variable reads the current [Default] [Recorder] when it is exported, and is null if there is none.
+// It panics if the name is already published.
+func Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		r := Default.Load()
+		if r == nil {
+			return nil
+		}
+		return r.Counts()
+	}))
+}
+
+// Record records an error with the [Default] [Recorder].
+//
+// It does nothing if the error is nil, or if there is no [Default] [Recorder].
+func Record(err error) {
+	r := Default.Load()
+	if r != nil {
+		r.Record(err)
+	}
+}
+
+// Options are the options of a [Recorder].
+type Options struct {
+	// Name is the name of the Prometheus metric.
+	// It must match the metric name grammar ([a-zA-Z_:][a-zA-Z0-9_:]*).
+	// The default value is "errors_total".
+	Name string
+	// Code returns the code of the error, for the "code" label.
+	// The codes are defined by the application (e.g. from a custom error type or a tag).
+	// If nil, the "code" label is not used.
+	Code func(err error) string
+	// Tags are the keys of the tags (see [errtag.Get]) used as labels.
+	// The label name is "tag_<key>", with the invalid characters replaced by "_".
+	// The label names must be distinct (e.g. "a.b" and "a-b" are both "tag_a_b").
+	Tags []string
+	// Temporary adds the "temporary" label (see [errtmp.Is]).
+	Temporary bool
+	// Function adds the "function" label, with the function of the first frame of the outermost stack (see [errstack.Frames]).
+	Function bool
+	// MaxSeries is the maximum number of distinct label sets.
+	// The errors exceeding it are counted in a series whose label values are all [OtherValue].
+	// The default value is 1000.
+	MaxSeries int
+}
+
+// OtherValue is the label value of the series counting the errors exceeding [Options.MaxSeries].
+const OtherValue = "other"
+
+// Recorder counts errors.
+//
+// It is safe for concurrent use.
+type Recorder struct {
+	opts   Options
+	labels []string
+	values []func(err error) string // The functions returning the label values, in the same order as labels.
+
+	mu     sync.RWMutex
+	series map[string]*series
+	other  *series
+}
+
+type series struct {
+	values []string
+	count  atomic.Int64
+}
+
+// NewRecorder returns a new [Recorder].
+//
+// It panics if [Options.Name] is not a valid metric name, or if the label names of [Options.Tags] are not distinct.
+func NewRecorder(opts Options) *Recorder {
+	if opts.Name == "" {
+		opts.Name = "errors_total"
+	}
+	if !isValidMetricName(opts.Name) {
+		panic("invalid metric name: " + strconv.Quote(opts.Name))
+	}
+	if opts.MaxSeries <= 0 {
+		opts.MaxSeries = 1000
+	}
+	opts.Tags = slices.Clone(opts.Tags)
+	r := &Recorder{
+		opts:   opts,
+		series: make(map[string]*series),
+	}
+	if opts.Code != nil {
+		r.addLabel("code", opts.Code)
+	}
+	for _, k := range opts.Tags {
+		name := "tag_" + sanitizeLabelName(k)
+		if slices.Contains(r.labels, name) {
+			panic("duplicate label name " + strconv.Quote(name) + " for tag " + strconv.Quote(k))
+		}
+		r.addLabel(name, func(err error) string {
+			return tagValue(err, k)
+		})
+	}
+	if opts.Temporary {
+		r.addLabel("temporary", func(err error) string {
+			return strconv.FormatBool(errtmp.Is(err))
+		})
+	}
+	if opts.Function {
+		r.addLabel("function", topFunction)
+	}
+	return r
+}
+
+func (r *Recorder) addLabel(name string, value func(err error) string) {
+	r.labels = append(r.labels, name)
+	r.values = append(r.values, value)
+}
+
+// Record records an error.
+//
+// It does nothing if the error is nil.
+func (r *Recorder) Record(err error) {
+	if err == nil {
+		return
+	}
+	bw := bytesWriterPool.Get()
+	defer bytesWriterPool.Put(bw)
+	*bw = r.appendKey(*bw, err)
+	r.mu.RLock()
+	s, ok := r.series[string(*bw)]
+	r.mu.RUnlock()
+	if !ok {
+		s = r.getOrCreateSeries(bw.String(), r.labelValues(err))
+	}
+	s.count.Add(1)
+}
+
+// appendKey appends the key of the series of an error to b: the label values separated by a 0 byte.
+func (r *Recorder) appendKey(b []byte, err error) []byte {
+	for i, value := range r.values {
+		if i > 0 {
+			b = append(b, 0)
+		}
+		b = append(b, value(err)...)
+	}
+	return b
+}
+
+// tagValue returns the value of the outermost tag with the given key (see [errtag.Get]).
+func tagValue(err error, key string) string {
+	for k, v := range errtag.All(err) {
+		if k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+func topFunction(err error) string {
+	for fs := range errstack.Frames(err) {
+		for f := range fs {
+			return f.Function
+		}
+	}
+	return ""
+}
+
+// labelValues returns the label values of an error.
+//
+// It is only called for a new series, the existing ones are found with the key (see [Recorder.appendKey]).
+func (r *Recorder) labelValues(err error) []string {
+	values := make([]string, len(r.values))
+	for i, value := range r.values {
+		values[i] = value(err)
+	}
+	return values
+}
+
+func (r *Recorder) getOrCreateSeries(key string, values []string) *series {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.series[key]
+	if ok {
+		return s
+	}
+	if len(r.series) >= r.opts.MaxSeries {
+		if r.other == nil {
+			r.other = &series{
+				values: slices.Repeat([]string{OtherValue}, len(r.labels)),
+			}
+		}
+		return r.other
+	}
+	s = &series{
+		values: values,
+	}
+	r.series[key] = s
+	return s
+}
+
+// Reset removes all the counters.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.series)
+	r.other = nil
+}
+
+// Count is the number of errors recorded with the given labels.
+type Count struct {
+	Labels []Label `json:"labels"`
+	Value  int64   `json:"value"`
+}
+
+// Label is a label of a [Count].
+type Label struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Counts returns the counts, sorted by label values.
+//
+// The [OtherValue] series, if any, is the last one.
+func (r *Recorder) Counts() []Count {
+	r.mu.RLock()
+	ss := make([]*series, 0, len(r.series)+1)
+	for _, s := range r.series {
+		ss = append(ss, s)
+	}
+	other := r.other
+	r.mu.RUnlock()
+	slices.SortFunc(ss, func(a, b *series) int {
+		return slices.Compare(a.values, b.values)
+	})
+	if other != nil {
+		ss = append(ss, other)
+	}
+	counts := make([]Count, 0, len(ss))
+	for _, s := range ss {
+		c := Count{
+			Labels: make([]Label, len(r.labels)),
+			Value:  s.count.Load(),
+		}
+		for i, name := range r.labels {
+			c.Labels[i] = Label{
+				Name:  name,
+				Value: s.values[i],
+			}
+		}
+		counts = append(counts, c)
+	}
+	return counts
+}
+
+// String implements [expvar.Var].
+//
+// It returns the counts (see [Recorder.Counts]) as a JSON array.
+func (r *Recorder) String() string {
+	b, err := json.Marshal(r.Counts())
+	if err != nil {
+		return "null"
+	}
+	return string(b)
+}
+
+// WritePrometheus writes the counts in the Prometheus text format.
+func (r *Recorder) WritePrometheus(w io.Writer) {
+	bw := bytesWriterPool.Get()
+	defer bytesWriterPool.Put(bw)
+	*bw = r.appendPrometheus(*bw)
+	_, _ = w.Write(*bw)
+}
+
+var bytesWriterPool = &bytesutil.WriterPool{}
+
+func (r *Recorder) appendPrometheus(b []byte) []byte {
+	b = append(b, "# HELP "...)
+	b = append(b, r.opts.Name...)
+	b = append(b, " Number of errors.\n# TYPE "...)
+	b = append(b, r.opts.Name...)
+	b = append(b, " counter\n"...)
+	for _, c := range r.Counts() {
+		b = append(b, r.opts.Name...)
+		if len(c.Labels) > 0 {
+			b = append(b, '{')
+			for i, l := range c.Labels {
+				if i > 0 {
+					b = append(b, ',')
+				}
+				b = append(b, l.Name...)
+				b = append(b, `="`...)
+				b = appendLabelValue(b, l.Value)
+				b = append(b, '"')
+			}
+			b = append(b, '}')
+		}
+		b = append(b, ' ')
+		b = strconv.AppendInt(b, c.Value, 10)
+		b = append(b, '\n')
+	}
+	return b
+}
+
+// ServeHTTP implements [http.Handler].
+//
+// It writes the counts in the Prometheus text format (see [Recorder.WritePrometheus]).
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WritePrometheus(w)
+}
+
+func appendLabelValue(b []byte, s string) []byte {
+	for i := range len(s) {
+		switch c := s[i]; c {
+		case '\\':
+			b = append(b, `\\`...)
+		case '"':
+			b = append(b, `\"`...)
+		case '\n':
+			b = append(b, `\n`...)
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+func sanitizeLabelName(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+func isValidMetricName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := range len(s) {
+		c := s[i]
+		if !(c == '_' || c == ':' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,208 @@
+package errmetrics_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pierrre/assert"
+	"github.com/pierrre/errors"
+	"github.com/pierrre/errors/errbase"
+	. "github.com/pierrre/errors/errmetrics"
+	"github.com/pierrre/errors/errtag"
+	"github.com/pierrre/errors/errtmp"
+)
+
+func Example() {
+	r := NewRecorder(Options{
+		Tags:      []string{"table"},
+		Temporary: true,
+	})
+	err := errbase.New("error")
+	r.Record(errtag.Wrap(err, "table", "users"))
+	r.Record(errtag.Wrap(err, "table", "users"))
+	r.Record(errtmp.Wrap(errtag.Wrap(err, "table", "orders"), false))
+	r.WritePrometheus(os.Stdout)
+	// Output:
+	// # HELP errors_total Number of errors.
+	// # TYPE errors_total counter
+	// errors_total{tag_table="orders",temporary="false"} 1
+	// errors_total{tag_table="users",temporary="true"} 2
+}
+
+func newTestError() error {
+	return errors.New("error")
+}
+
+func TestRecord(t *testing.T) {
+	r := NewRecorder(Options{
+		Code: func(err error) string {
+			return err.Error()
+		},
+		Tags:      []string{"a.b"},
+		Temporary: true,
+		Function:  true,
+	})
+	r.Record(nil)
+	err := errtag.Wrap(newTestError(), "a.b", "c")
+	r.Record(err)
+	r.Record(err)
+	assert.DeepEqual(t, r.Counts(), []Count{
+		{
+			Labels: []Label{
+				{Name: "code", Value: "error"},
+				{Name: "tag_a_b", Value: "c"},
+				{Name: "temporary", Value: "true"},
+				{Name: "function", Value: "github.com/pierrre/errors/errmetrics_test.newTestError"},
+			},
+			Value: 2,
+		},
+	})
+	r.Reset()
+	assert.SliceEmpty(t, r.Counts())
+}
+
+func TestRecordNoLabels(t *testing.T) {
+	r := NewRecorder(Options{Name: "test_errors_total"})
+	r.Record(errbase.New("error"))
+	var sb strings.Builder
+	r.WritePrometheus(&sb)
+	assert.Equal(t, sb.String(), "# HELP test_errors_total Number of errors.\n# TYPE test_errors_total counter\ntest_errors_total 1\n")
+}
+
+func TestRecordFunctionNoStack(t *testing.T) {
+	r := NewRecorder(Options{Function: true})
+	r.Record(errbase.New("error"))
+	counts := r.Counts()
+	assert.SliceLen(t, counts, 1)
+	assert.Equal(t, counts[0].Labels[0].Value, "")
+}
+
+func TestMaxSeries(t *testing.T) {
+	r := NewRecorder(Options{
+		Code: func(err error) string {
+			return err.Error()
+		},
+		MaxSeries: 2,
+	})
+	for i := range 5 {
+		r.Record(errbase.New(fmt.Sprint(i)))
+	}
+	r.Record(errbase.New("0"))
+	counts := r.Counts()
+	assert.SliceLen(t, counts, 3)
+	assert.Equal(t, counts[0].Labels[0].Value, "0")
+	assert.Equal(t, counts[0].Value, 2)
+	assert.Equal(t, counts[1].Labels[0].Value, "1")
+	assert.Equal(t, counts[2].Labels[0].Value, OtherValue)
+	assert.Equal(t, counts[2].Value, 3)
+}
+
+func TestPrometheusEscape(t *testing.T) {
+	r := NewRecorder(Options{
+		Code: func(err error) string {
+			return err.Error()
+		},
+	})
+	r.Record(errbase.New("a\"b\\c\nd"))
+	var sb strings.Builder
+	r.WritePrometheus(&sb)
+	assert.StringContains(t, sb.String(), `errors_total{code="a\"b\\c\nd"} 1`)
+}
+
+func TestServeHTTP(t *testing.T) {
+	r := NewRecorder(Options{Temporary: true})
+	r.Record(errbase.New("error"))
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, w.Code, http.StatusOK)
+	assert.StringHasPrefix(t, w.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+	assert.StringContains(t, w.Body.String(), `errors_total{temporary="true"} 1`)
+}
+
+func TestNewRecorderInvalidName(t *testing.T) {
+	for _, name := range []string{"1errors", "errors-total", "errors total"} {
+		assert.Panics(t, func() {
+			NewRecorder(Options{Name: name})
+		})
+	}
+	NewRecorder(Options{Name: "app:errors_total_2"})
+}
+
+func TestNewRecorderDuplicateTags(t *testing.T) {
+	assert.Panics(t, func() {
+		NewRecorder(Options{Tags: []string{"a.b", "a-b"}})
+	})
+	assert.Panics(t, func() {
+		NewRecorder(Options{Tags: []string{"a", "a"}})
+	})
+}
+
+func TestExpvar(t *testing.T) {
+	r := NewRecorder(Options{Temporary: true})
+	var _ expvar.Var = r
+	r.Record(errbase.New("error"))
+	var counts []Count
+	err := json.Unmarshal([]byte(r.String()), &counts)
+	assert.NoError(t, err)
+	assert.DeepEqual(t, counts, r.Counts())
+}
+
+func TestPublish(t *testing.T) {
+	r := NewRecorder(Options{Temporary: true})
+	old := Default.Swap(r)
+	defer Default.Store(old)
+	Publish("errmetrics_test")
+	Record(errbase.New("error"))
+	v := expvar.Get("errmetrics_test")
+	assert.NotZero(t, v)
+	var counts []Count
+	err := json.Unmarshal([]byte(v.String()), &counts)
+	assert.NoError(t, err)
+	assert.DeepEqual(t, counts, r.Counts())
+	Default.Store(nil)
+	assert.Equal(t, v.String(), "null")
+	assert.Panics(t, func() {
+		Publish("errmetrics_test")
+	})
+}
+
+func TestDefault(t *testing.T) {
+	r := NewRecorder(Options{})
+	old := Default.Swap(r)
+	defer Default.Store(old)
+	Record(errbase.New("error"))
+	assert.Equal(t, r.Counts()[0].Value, 1)
+	Default.Store(nil)
+	Record(errbase.New("error"))
+}
+
+func TestRecordAllocs(t *testing.T) {
+	r := NewRecorder(Options{
+		Tags:      []string{"a"},
+		Temporary: true,
+	})
+	err := errtag.Wrap(newTestError(), "a", "b")
+	r.Record(err)
+	assert.AllocsPerRun(t, 100, func() {
+		r.Record(err)
+	}, 0)
+}
+
+func BenchmarkRecord(b *testing.B) {
+	r := NewRecorder(Options{
+		Tags:      []string{"a"},
+		Temporary: true,
+		Function:  true,
+	})
+	err := errtag.Wrap(newTestError(), "a", "b")
+	for b.Loop() {
+		r.Record(err)
+	}
+}
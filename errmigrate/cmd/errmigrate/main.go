@@ -0,0 +1,105 @@
+// Package main provides a command that rewrites Go source files to use the errors library (see [errmigrate]).
+//
+// Usage:
+//
+//	errmigrate [-w] [-l] path...
+//
+// The paths are files or directories (recursively, excluding vendor, testdata and hidden directories).
+// By default, the rewritten source is printed to stdout.
+// The cases that can't be converted safely are reported to stderr.
+// The files that can't be rewritten (e.g. because of a syntax error) are reported to stderr, and the other files are still processed.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pierrre/errors"
+	"github.com/pierrre/errors/errmigrate"
+)
+
+func main() {
+	write := flag.Bool("w", false, "write the result to the source file instead of stdout")
+	list := flag.Bool("l", false, "list the files whose content is changed")
+	flag.Parse()
+	ok := true
+	for _, p := range flag.Args() {
+		err := walk(p, func(fp string) {
+			err := process(fp, *write, *list)
+			if err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, err)
+				ok = false
+			}
+		})
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			ok = false
+		}
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// walk calls f for each Go file.
+//
+// The errors of f don't stop the walk, so they must be reported by f.
+func walk(root string, f func(fp string)) error {
+	err := filepath.WalkDir(root, func(fp string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if fp != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if fp != root && !strings.HasSuffix(fp, ".go") {
+			return nil
+		}
+		f(fp)
+		return nil
+	})
+	return errors.Wrap(err, "walk")
+}
+
+func process(fp string, write bool, list bool) error {
+	src, err := os.ReadFile(fp)
+	if err != nil {
+		return errors.Wrapf(err, "read %s", fp)
+	}
+	out, issues, err := errmigrate.Rewrite(fp, src)
+	if err != nil {
+		return errors.Wrapf(err, "rewrite %s", fp)
+	}
+	for _, issue := range issues {
+		_, _ = fmt.Fprintln(os.Stderr, issue)
+	}
+	changed := !bytes.Equal(src, out)
+	if list && changed {
+		_, _ = fmt.Println(fp)
+	}
+	if write {
+		if changed {
+			err = os.WriteFile(fp, out, 0o600)
+			if err != nil {
+				return errors.Wrapf(err, "write %s", fp)
+			}
+		}
+		return nil
+	}
+	if !list {
+		_, err = os.Stdout.Write(out)
+		if err != nil {
+			return errors.Wrap(err, "write stdout")
+		}
+	}
+	return nil
+}
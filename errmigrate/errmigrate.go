@@ -0,0 +1,470 @@
+// Package errmigrate rewrites Go source files to use the errors library.
+//
+// It converts:
+//   - fmt.Errorf("msg: %w", err) to errors.Wrap(err, "msg")
+//   - fmt.Errorf("msg %s: %w", x, err) to errors.Wrapf(err, "msg %s", x)
+//   - fmt.Errorf("msg %s", x) to errors.Newf("msg %s", x)
+//   - fmt.Errorf("msg") to errors.New("msg")
+//   - the std "errors" package to the errors library, which is a drop-in replacement
+//   - the "github.com/pkg/errors" package to the errors library (WithStack, WithMessage, WithMessagef, Errorf, Cause, etc.)
+//   - errors.New/fmt.Errorf in package-level variable initializers to errbase.New/errbase.Newf
+//
+// The rewriting is syntactic: it doesn't require the code to compile or type check.
+// An added import is renamed if its name is already used by the file.
+// The cases that can't be converted safely are reported as [Issue].
+package errmigrate
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/pierrre/errors"
+	"github.com/pierrre/errors/internal/fmtverb"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/imports"
+)
+
+const (
+	modulePath    = "github.com/pierrre/errors"
+	errbasePath   = modulePath + "/errbase"
+	erriterPath   = modulePath + "/erriter"
+	errmsgPath    = modulePath + "/errmsg"
+	errstackPath  = modulePath + "/errstack"
+	stdErrorsPath = "errors"
+	pkgErrorsPath = "github.com/pkg/errors"
+	fmtPath       = "fmt"
+)
+
+// Issue is a case that can't be converted safely.
+type Issue struct {
+	Pos     token.Position
+	Message string
+}
+
+func (i Issue) String() string {
+	return i.Pos.String() + ": " + i.Message
+}
+
+// Rewrite rewrites the source of a Go file.
+//
+// The filename is only used for the positions of the issues.
+// It returns the original source if there is nothing to rewrite.
+func Rewrite(filename string, src []byte) ([]byte, []Issue, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parse")
+	}
+	r := &rewriter{
+		fset:    fset,
+		file:    file,
+		src:     src,
+		imports: fileImports(file),
+		globals: globalCalls(file),
+	}
+	out := r.rewriteNode(file, 0, len(src))
+	if !r.changed && !r.importsToRewrite() {
+		return src, r.issues, nil
+	}
+	res, err := r.fixImports(filename, out)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, r.issues, nil
+}
+
+type rewriter struct {
+	fset       *token.FileSet
+	file       *ast.File
+	src        []byte
+	imports    map[string]string // name -> path
+	globals    map[ast.Node]bool
+	newImports []newImport
+	changed    bool
+	issues     []Issue
+}
+
+// newImport is an import added to the rewritten file.
+type newImport struct {
+	name string // The local name, which differs from the last element of the path if it is already used in the file.
+	path string
+}
+
+// fileImports returns the imports of a file, by name.
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := p[strings.LastIndex(p, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = p
+	}
+	return imports
+}
+
+// globalCalls returns the calls executed in package-level variable initializers, and their function selectors.
+func globalCalls(file *ast.File) map[ast.Node]bool {
+	globals := make(map[ast.Node]bool)
+	for _, decl := range file.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.VAR {
+			continue
+		}
+		ast.Inspect(gd, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.CallExpr:
+				globals[n] = true
+				globals[n.Fun] = true
+			}
+			return true
+		})
+	}
+	return globals
+}
+
+// importName returns the name used to reference a package of the errors library in the rewritten file, and records the import if it is missing.
+//
+// The root package is also referenced by the name of the std "errors" and "github.com/pkg/errors" imports, because they are rewritten to it.
+// A new import is named after the last element of its path, with a numeric suffix if the name is already used by the file.
+func (r *rewriter) importName(p string) string {
+	paths := []string{p}
+	if p == modulePath {
+		paths = append(paths, stdErrorsPath, pkgErrorsPath)
+	}
+	for _, ip := range paths {
+		for name, ip2 := range r.imports {
+			if ip2 == ip && name != "_" && name != "." {
+				return name
+			}
+		}
+	}
+	for _, imp := range r.newImports {
+		if imp.path == p {
+			return imp.name
+		}
+	}
+	base := p[strings.LastIndex(p, "/")+1:]
+	name := base
+	for i := 2; r.isNameUsed(name); i++ {
+		name = base + strconv.Itoa(i)
+	}
+	r.newImports = append(r.newImports, newImport{
+		name: name,
+		path: p,
+	})
+	return name
+}
+
+// isNameUsed returns true if a name is used by an import or a package-level declaration of the file.
+func (r *rewriter) isNameUsed(name string) bool {
+	if _, ok := r.imports[name]; ok {
+		return true
+	}
+	for _, imp := range r.newImports {
+		if imp.name == name {
+			return true
+		}
+	}
+	return r.file.Scope.Lookup(name) != nil
+}
+
+func (r *rewriter) importsToRewrite() bool {
+	for _, p := range r.imports {
+		if p == stdErrorsPath || p == pkgErrorsPath {
+			return true
+		}
+	}
+	return false
+}
+
+// rewriteNode returns the source between the start and end offsets, with the conversions applied to the descendants of root.
+//
+// The descendants outside of the range are ignored.
+func (r *rewriter) rewriteNode(root ast.Node, start, end int) string {
+	type replacement struct {
+		start, end int
+		text       string
+	}
+	var reps []replacement
+	ast.Inspect(root, func(n ast.Node) bool {
+		if n == nil || n == root {
+			return true
+		}
+		if r.offset(n.End()) <= start || r.offset(n.Pos()) >= end {
+			return false
+		}
+		var text string
+		var ok bool
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			text, ok = r.convertCall(n)
+		case *ast.SelectorExpr:
+			text, ok = r.convertSelector(n)
+		}
+		if ok {
+			reps = append(reps, replacement{
+				start: r.offset(n.Pos()),
+				end:   r.offset(n.End()),
+				text:  text,
+			})
+			r.changed = true
+			return false
+		}
+		return true
+	})
+	var b strings.Builder
+	pos := start
+	for _, rep := range reps {
+		b.Write(r.src[pos:rep.start])
+		b.WriteString(rep.text)
+		pos = rep.end
+	}
+	b.Write(r.src[pos:end])
+	return b.String()
+}
+
+// rewriteArg returns the source of an argument of a call, with the conversions applied.
+func (r *rewriter) rewriteArg(call *ast.CallExpr, arg ast.Expr) string {
+	return r.rewriteNode(call, r.offset(arg.Pos()), r.offset(arg.End()))
+}
+
+func (r *rewriter) offset(pos token.Pos) int {
+	return r.fset.Position(pos).Offset
+}
+
+func (r *rewriter) issue(pos token.Pos, format string, args ...any) {
+	r.issues = append(r.issues, Issue{
+		Pos:     r.fset.Position(pos),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// importPath returns the import path of a package-qualified selector.
+func (r *rewriter) importPath(sel *ast.SelectorExpr) (string, bool) {
+	id, ok := sel.X.(*ast.Ident)
+	if !ok || id.Obj != nil { // A resolved object is a local identifier, not a package.
+		return "", false
+	}
+	p, ok := r.imports[id.Name]
+	return p, ok
+}
+
+// convertSelector converts a package-qualified selector.
+func (r *rewriter) convertSelector(sel *ast.SelectorExpr) (string, bool) {
+	p, ok := r.importPath(sel)
+	if !ok {
+		return "", false
+	}
+	name := sel.X.(*ast.Ident).Name //nolint:forcetypeassert // Checked by importPath.
+	global := r.globals[sel]
+	switch p {
+	case stdErrorsPath:
+		if global && sel.Sel.Name == "New" {
+			return r.selector(errbasePath, "New"), true
+		}
+	case pkgErrorsPath:
+		return r.convertPkgErrors(sel, name, global)
+	}
+	return "", false
+}
+
+func (r *rewriter) convertPkgErrors(sel *ast.SelectorExpr, name string, global bool) (string, bool) {
+	switch sel.Sel.Name {
+	case "New":
+		if global {
+			return r.selector(errbasePath, "New"), true
+		}
+	case "Errorf":
+		if global {
+			return r.selector(errbasePath, "Newf"), true
+		}
+		return name + ".Newf", true
+	case "WithStack":
+		return r.selector(errstackPath, "Wrap"), true
+	case "WithMessage":
+		return r.selector(errmsgPath, "Wrap"), true
+	case "WithMessagef":
+		return r.selector(errmsgPath, "Wrapf"), true
+	case "Cause":
+		return r.selector(erriterPath, "Cause"), true
+	case "Wrap", "Wrapf", "Is", "As", "Unwrap":
+	default:
+		r.issue(sel.Pos(), "%s.%s has no equivalent", pkgErrorsPath, sel.Sel.Name)
+	}
+	return "", false
+}
+
+// selector returns a selector for a package of the errors library, and records the import.
+func (r *rewriter) selector(p string, name string) string {
+	return r.importName(p) + "." + name
+}
+
+func (r *rewriter) convertCall(call *ast.CallExpr) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	p, ok := r.importPath(sel)
+	if !ok || sel.Sel.Name != "Errorf" {
+		return "", false
+	}
+	switch p {
+	case fmtPath:
+		return r.convertErrorf(call)
+	case pkgErrorsPath:
+		return r.convertConstErrorf(call)
+	}
+	return "", false
+}
+
+func (r *rewriter) convertErrorf(call *ast.CallExpr) (string, bool) {
+	if len(call.Args) == 0 {
+		return "", false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		r.issue(call.Pos(), "fmt.Errorf with a non-literal format can't be converted")
+		return "", false
+	}
+	format, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+	if text, ok := r.convertConstErrorf(call); ok {
+		return text, true
+	}
+	if r.globals[call] {
+		return r.selector(errbasePath, "Newf") + r.rewriteNode(call, r.offset(call.Lparen), r.offset(call.End())), true
+	}
+	if !fmtverb.Has(format, 'w') {
+		return r.selector(modulePath, "Newf") + r.rewriteNode(call, r.offset(call.Lparen), r.offset(call.End())), true
+	}
+	msg, ok := fmtverb.CutWrapSuffix(format)
+	if !ok || len(call.Args) < 2 || call.Ellipsis.IsValid() {
+		r.issue(call.Pos(), "fmt.Errorf with %%w can only be converted if the format ends with \": %%w\" and the error is the last argument")
+		return "", false
+	}
+	args := call.Args[1 : len(call.Args)-1]
+	if len(args) == 0 {
+		// Wrap doesn't format the message, so escaped percent signs must be unescaped.
+		msg, ok = fmtverb.Unescape(msg)
+		if !ok {
+			r.issue(call.Pos(), "fmt.Errorf with verbs and no arguments can't be converted")
+			return "", false
+		}
+	}
+	var b strings.Builder
+	if len(args) == 0 {
+		b.WriteString(r.selector(modulePath, "Wrap"))
+	} else {
+		b.WriteString(r.selector(modulePath, "Wrapf"))
+	}
+	b.WriteString("(")
+	b.WriteString(r.rewriteArg(call, call.Args[len(call.Args)-1]))
+	b.WriteString(", ")
+	b.WriteString(quote(msg, lit.Value))
+	for _, arg := range args {
+		b.WriteString(", ")
+		b.WriteString(r.rewriteArg(call, arg))
+	}
+	b.WriteString(")")
+	return b.String(), true
+}
+
+// convertConstErrorf converts an Errorf call with a constant message and no arguments to New.
+//
+// Newf would be reported by the analyzer, because it doesn't need to format the message.
+func (r *rewriter) convertConstErrorf(call *ast.CallExpr) (string, bool) {
+	if len(call.Args) != 1 {
+		return "", false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	format, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+	msg, ok := fmtverb.Unescape(format)
+	if !ok {
+		return "", false
+	}
+	p := modulePath
+	if r.globals[call] {
+		p = errbasePath
+	}
+	return r.selector(p, "New") + "(" + quote(msg, lit.Value) + ")", true
+}
+
+// quote quotes s with the same kind of quote as the original literal.
+func quote(s string, original string) string {
+	if strings.HasPrefix(original, "`") && !strings.Contains(s, "`") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// fixImports rewrites the imports of the rewritten source and formats it.
+func (r *rewriter) fixImports(filename string, src string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse rewritten source")
+	}
+	for _, p := range []string{stdErrorsPath, pkgErrorsPath} {
+		astutil.RewriteImport(fset, file, p, modulePath)
+	}
+	for _, imp := range r.newImports {
+		if imp.name == imp.path[strings.LastIndex(imp.path, "/")+1:] {
+			astutil.AddImport(fset, file, imp.path)
+		} else {
+			astutil.AddNamedImport(fset, file, imp.name, imp.path)
+		}
+	}
+	if !astutil.UsesImport(file, fmtPath) {
+		astutil.DeleteImport(fset, file, fmtPath)
+	}
+	if countImports(file, modulePath) > 1 {
+		r.issue(r.file.Package, "%s is imported multiple times", modulePath)
+	}
+	buf := new(bytes.Buffer)
+	err = format.Node(buf, fset, file)
+	if err != nil {
+		return nil, errors.Wrap(err, "format")
+	}
+	// Group the std imports separately from the other imports, like goimports.
+	res, err := imports.Process(filename, buf.Bytes(), &imports.Options{
+		Comments:   true,
+		TabIndent:  true,
+		TabWidth:   8,
+		FormatOnly: true,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "format imports")
+	}
+	return res, nil
+}
+
+func countImports(file *ast.File, p string) int {
+	n := 0
+	for _, spec := range file.Imports {
+		if spec.Path.Value == strconv.Quote(p) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,43 @@
+package errmigrate_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pierrre/assert"
+	. "github.com/pierrre/errors/errmigrate"
+)
+
+func TestRewrite(t *testing.T) {
+	fps, err := filepath.Glob(filepath.Join("testdata", "*.input"))
+	assert.NoError(t, err)
+	assert.SliceNotEmpty(t, fps)
+	for _, fp := range fps {
+		name := strings.TrimSuffix(filepath.Base(fp), ".input")
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(fp)
+			assert.NoError(t, err)
+			out, issues, err := Rewrite(filepath.Base(fp), src)
+			assert.NoError(t, err)
+			golden, err := os.ReadFile(filepath.Join("testdata", name+".golden"))
+			assert.NoError(t, err)
+			assert.Equal(t, string(out), string(golden))
+			var issuesString string
+			for _, issue := range issues {
+				issuesString += issue.String() + "\n"
+			}
+			expectedIssues, err := os.ReadFile(filepath.Join("testdata", name+".issues"))
+			if !os.IsNotExist(err) {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, issuesString, string(expectedIssues))
+		})
+	}
+}
+
+func TestRewriteParseError(t *testing.T) {
+	_, _, err := Rewrite("test.go", []byte("invalid"))
+	assert.Error(t, err)
+}
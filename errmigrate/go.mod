@@ -0,0 +1,21 @@
+module github.com/pierrre/errors/errmigrate
+
+go 1.27.0
+
+require (
+	github.com/pierrre/assert v0.15.6
+	github.com/pierrre/errors v0.0.0-00010101000000-000000000000
+	golang.org/x/tools v0.51.0
+)
+
+require (
+	github.com/pierrre/compare v1.5.0 // indirect
+	github.com/pierrre/go-libs v0.34.8 // indirect
+	github.com/pierrre/pretty v0.26.6 // indirect
+	golang.org/x/mod v0.41.0 // indirect
+	golang.org/x/sync v0.23.0 // indirect
+)
+
+// The migration tool is developed with the library of the same repository.
+// Require the released version of the library and remove this directive before tagging the module.
+replace github.com/pierrre/errors => ../
@@ -5,6 +5,9 @@ import (
 	"fmt"
 
 	"github.com/pierrre/errors/errappend"
+	"github.com/pierrre/errors/errverbose"
+	"github.com/pierrre/errors/internal/lazyfmt"
+	"github.com/pierrre/go-libs/syncutil/atomicutil"
 )
 
 // Wrap adds a message to an error.
@@ -28,10 +31,33 @@ func Wrap(err error, msg string) error {
 // Wrapf calls [Wrap] with a formatted message.
 //
 // It doesn't support the %w verb.
+//
+// If [LazyFormat] is enabled, the message may be formatted lazily.
 func Wrapf(err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+	if LazyFormat.Load() && lazyfmt.Supported(format, args) {
+		lm := &lazyMessage{
+			error: err,
+		}
+		lm.msg.Init(format, args)
+		return lm
+	}
 	return Wrap(err, fmt.Sprintf(format, args...))
 }
 
+// LazyFormat enables the lazy formatting of the messages in [Wrapf] (and the github.com/pierrre/errors Wrapf and Newf functions).
+//
+// The message is formatted the first time the error message is requested, and the result is cached.
+// It avoids the cost of formatting for errors that are never displayed (e.g. ignored or retried).
+//
+// In order to prevent inconsistent messages, it is only used if the arguments are values of a basic type (bool, string, integer, float, complex), which can't be modified later.
+// Other types (pointers, slices, maps, structs, errors, etc.) and more than 4 arguments are formatted eagerly.
+//
+// The default value is false.
+var LazyFormat atomicutil.Value[bool]
+
 type message struct {
 	error
 	msg string
@@ -41,6 +67,10 @@ func (err *message) Unwrap() error {
 	return err.error
 }
 
+func (err *message) Format(s fmt.State, verb rune) {
+	errverbose.Format(s, verb, err)
+}
+
 func (err *message) Error() string {
 	return errappend.String(err)
 }
@@ -51,3 +81,30 @@ func (err *message) ErrorAppend(b []byte) []byte {
 	b = errappend.Append(b, err.error)
 	return b
 }
+
+type lazyMessage struct {
+	error
+	msg lazyfmt.Message
+}
+
+func (err *lazyMessage) Unwrap() error {
+	return err.error
+}
+
+func (err *lazyMessage) Format(s fmt.State, verb rune) {
+	errverbose.Format(s, verb, err)
+}
+
+func (err *lazyMessage) Error() string {
+	return errappend.String(err)
+}
+
+func (err *lazyMessage) ErrorAppend(b []byte) []byte {
+	msg := err.msg.String()
+	if msg != "" {
+		b = append(b, msg...)
+		b = append(b, ": "...)
+	}
+	b = errappend.Append(b, err.error)
+	return b
+}
@@ -61,6 +61,61 @@ func TestWrapfAllocs(t *testing.T) {
 	testSink = res
 }
 
+func enableLazyFormat(tb testing.TB) {
+	tb.Helper()
+	old := LazyFormat.Swap(true)
+	tb.Cleanup(func() {
+		LazyFormat.Store(old)
+	})
+}
+
+func TestWrapfLazy(t *testing.T) {
+	enableLazyFormat(t)
+	args := []any{1, "a"}
+	err := Wrapf(errbase.New("error"), "test %d %s", args...)
+	args[0] = 2
+	assert.ErrorEqual(t, err, "test 1 a: error")
+	assert.ErrorEqual(t, err, "test 1 a: error")
+}
+
+func TestWrapfLazyNil(t *testing.T) {
+	enableLazyFormat(t)
+	err := Wrapf(nil, "test %d", 1)
+	assert.NoError(t, err)
+}
+
+func TestWrapfLazyEmpty(t *testing.T) {
+	enableLazyFormat(t)
+	err := Wrapf(errbase.New("error"), "%s", "")
+	assert.ErrorEqual(t, err, "error")
+}
+
+func TestWrapfLazyUnsupported(t *testing.T) {
+	enableLazyFormat(t)
+	s := []int{1}
+	err := Wrapf(errbase.New("error"), "test %v", s)
+	s[0] = 2
+	assert.ErrorEqual(t, err, "test [1]: error")
+}
+
+func TestWrapfLazyUnwrap(t *testing.T) {
+	enableLazyFormat(t)
+	err1 := errbase.New("error")
+	err2 := Wrapf(err1, "test %d", 1)
+	assert.ErrorIs(t, err2, err1)
+	assert.Equal(t, errors.Unwrap(err2), err1)
+}
+
+func TestWrapfLazyAllocs(t *testing.T) {
+	enableLazyFormat(t)
+	err := errbase.New("error")
+	var res error
+	assert.AllocsPerRun(t, 100, func() {
+		res = Wrapf(err, "test %d", 1)
+	}, 1)
+	testSink = res
+}
+
 func TestErrorAllocs(t *testing.T) {
 	err := errbase.New("error")
 	err = Wrap(err, "test")
@@ -85,6 +140,29 @@ func BenchmarkWrapf(b *testing.B) {
 	}
 }
 
+func BenchmarkWrapfLazy(b *testing.B) {
+	enableLazyFormat(b)
+	err := errbase.New("error")
+	for b.Loop() {
+		_ = Wrapf(err, "test %d", 1)
+	}
+}
+
+func BenchmarkWrapfLazyError(b *testing.B) {
+	enableLazyFormat(b)
+	err := errbase.New("error")
+	for b.Loop() {
+		_ = Wrapf(err, "test %d", 1).Error()
+	}
+}
+
+func BenchmarkWrapfEagerError(b *testing.B) {
+	err := errbase.New("error")
+	for b.Loop() {
+		_ = Wrapf(err, "test %d", 1).Error()
+	}
+}
+
 func BenchmarkError(b *testing.B) {
 	err := errbase.New("error")
 	err = Wrap(err, "test")
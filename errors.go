@@ -5,6 +5,7 @@ package errors
 
 import (
 	std_errors "errors"
+	"io"
 	"runtime"
 	"strings"
 	"testing"
@@ -13,6 +14,7 @@ import (
 	"github.com/pierrre/errors/errjoin"
 	"github.com/pierrre/errors/errmsg"
 	"github.com/pierrre/errors/errstack"
+	"github.com/pierrre/errors/internal/fmtverb"
 	"github.com/pierrre/go-libs/syncutil/atomicutil"
 )
 
@@ -21,11 +23,12 @@ var ErrUnsupported = std_errors.ErrUnsupported
 
 // New returns a new error with a message and a stack.
 //
+// The message and the stack are stored in a single error, so [Unwrap] returns nil.
+//
 // Warning: don't use this function to create a global (sentinel) error, as it will contain the stack of the (main) goroutine creating it.
 // Use [errbase.New] instead.
 func New(msg string) error {
-	err := errbase.New(msg)
-	err = errstack.WrapSkip(err, 1)
+	var err error = newStackMessage(nil, msg, nil, 1)
 	report := ReportGlobalInit.Load()
 	if report != nil {
 		checkGlobalInit(err, report)
@@ -35,13 +38,21 @@ func New(msg string) error {
 
 // Newf returns a new error with a formatted message and a stack.
 //
-// It supports the %w verb.
+// It supports the %w verb, including with flags or an argument index (e.g. "%[1]w").
+// In this case, the stack is added with a separate wrapper, and [Unwrap] returns the formatted error.
+// Otherwise, [Unwrap] returns nil.
 //
 // Warning: don't use this function to create a global (sentinel) error, as it will contain the stack of the (main) goroutine creating it.
 // Use [errbase.Newf] instead.
 func Newf(format string, args ...any) error {
-	err := errbase.Newf(format, args...)
-	err = errstack.WrapSkip(err, 1)
+	var err error
+	if fmtverb.Has(format, 'w') {
+		err = errbase.Newf(format, args...)
+		err = errstack.WrapSkip(err, 1)
+	} else {
+		msg, lazyMsg := formatMessage(format, args)
+		err = newStackMessage(nil, msg, lazyMsg, 1)
+	}
 	report := ReportGlobalInit.Load()
 	if report != nil {
 		checkGlobalInit(err, report)
@@ -99,10 +110,11 @@ func checkGlobalInit(err error, report func(error)) {
 }
 
 // Wrap adds a message to an error, and a stack if it doesn't have one.
+//
+// If the error doesn't have a stack, the message and the stack are stored in a single wrapper, so [Unwrap] returns the original error.
 func Wrap(err error, msg string) error {
 	if err != nil {
-		err = errstack.EnsureSkip(err, 1)
-		err = errmsg.Wrap(err, msg)
+		err = wrapSkip(err, msg, 1)
 	}
 	return err
 }
@@ -112,12 +124,103 @@ func Wrap(err error, msg string) error {
 // It doesn't support the %w verb.
 func Wrapf(err error, format string, args ...any) error {
 	if err != nil {
-		err = errstack.EnsureSkip(err, 1)
-		err = errmsg.Wrapf(err, format, args...)
+		err = wrapfSkip(err, 1, format, args...)
 	}
 	return err
 }
 
+// wrapSkip adds a message and a stack (if it doesn't have one) to a non-nil error.
+//
+// If the error doesn't have a stack, it uses a single wrapper for both.
+func wrapSkip(err error, msg string, skip int) error {
+	if msg == "" || hasStack(err) {
+		err = errstack.EnsureSkip(err, skip+1)
+		return errmsg.Wrap(err, msg)
+	}
+	return newStackMessage(err, msg, nil, skip+1)
+}
+
+// wrapfSkip is like [wrapSkip], but with a formatted message, which may be formatted lazily (see [errmsg.LazyFormat]).
+func wrapfSkip(err error, skip int, format string, args ...any) error {
+	if hasStack(err) {
+		return errmsg.Wrapf(err, format, args...)
+	}
+	msg, lazyMsg := formatMessage(format, args)
+	if msg == "" && lazyMsg == nil {
+		return errstack.WrapSkip(err, skip+1)
+	}
+	return newStackMessage(err, msg, lazyMsg, skip+1)
+}
+
+// WrapDefer calls [Wrap] on the error pointed to by errp, if it is not nil.
+//
+// It is intended to be deferred, with a named error result:
+//
+//	func myFunc() (err error) {
+//		defer errors.WrapDefer(&err, "myFunc")
+//		...
+//	}
+//
+// The stack is captured in the function that deferred it.
+func WrapDefer(errp *error, msg string) {
+	err := *errp
+	if err != nil {
+		*errp = wrapSkip(err, msg, 1)
+	}
+}
+
+// WrapfDefer calls [Wrapf] on the error pointed to by errp, if it is not nil.
+//
+// See [WrapDefer].
+//
+// It doesn't support the %w verb.
+func WrapfDefer(errp *error, format string, args ...any) {
+	err := *errp
+	if err != nil {
+		*errp = wrapfSkip(err, 1, format, args...)
+	}
+}
+
+// CloseDefer closes c, and joins the returned error (with the message "close") to the error pointed to by errp.
+//
+// It is intended to be deferred, with a named error result:
+//
+//	func myFunc() (err error) {
+//		f, err := os.Open("file")
+//		if err != nil {
+//			return errors.Wrap(err, "open")
+//		}
+//		defer errors.CloseDefer(&err, f)
+//		...
+//	}
+//
+// The stack is captured in the function that deferred it.
+func CloseDefer(errp *error, c io.Closer) {
+	err := c.Close()
+	if err != nil {
+		err = wrapSkip(err, "close", 1)
+		*errp = joinDefer(*errp, err)
+	}
+}
+
+// CallDefer calls f, and joins the returned error to the error pointed to by errp.
+//
+// See [CloseDefer].
+func CallDefer(errp *error, f func() error) {
+	err := f()
+	if err != nil {
+		err = errstack.EnsureSkip(err, 1)
+		*errp = joinDefer(*errp, err)
+	}
+}
+
+func joinDefer(err error, deferErr error) error {
+	if err == nil {
+		return deferErr
+	}
+	return errjoin.Join(err, deferErr)
+}
+
 // As is an alias for [std_errors.As].
 func As(err error, target any) bool {
 	return std_errors.As(err, target)
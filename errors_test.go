@@ -2,6 +2,7 @@ package errors_test
 
 import (
 	"fmt"
+	"io"
 	"io/fs"
 	"slices"
 	"testing"
@@ -9,7 +10,9 @@ import (
 	"github.com/pierrre/assert"
 	. "github.com/pierrre/errors"
 	"github.com/pierrre/errors/errbase"
+	"github.com/pierrre/errors/errmsg"
 	"github.com/pierrre/errors/errstack"
+	"github.com/pierrre/errors/errverbose"
 )
 
 var testSink any
@@ -41,6 +44,43 @@ func TestNewf(t *testing.T) {
 	assert.SliceLen(t, sfs, 1)
 }
 
+func TestNewVerbose(t *testing.T) {
+	err := New("error")
+	s := errverbose.String(err)
+	assert.RegexpMatch(t, `^error\nstack:\n(.+\n\t.+:\d+\n)+\n$`, s)
+	checkStackFunction(t, err, "github.com/pierrre/errors_test.TestNewVerbose")
+	assert.Zero(t, Unwrap(err))
+}
+
+func TestNewfWrapVerb(t *testing.T) {
+	errBase := errbase.New("error")
+	err := Newf("test: %w", errBase)
+	assert.ErrorEqual(t, err, "test: error")
+	assert.ErrorIs(t, err, errBase)
+	checkStackFunction(t, err, "github.com/pierrre/errors_test.TestNewfWrapVerb")
+}
+
+func TestNewfWrapVerbIndexed(t *testing.T) {
+	errBase := errbase.New("error")
+	err := Newf("test %[1]w", errBase)
+	assert.ErrorEqual(t, err, "test error")
+	assert.ErrorIs(t, err, errBase)
+	checkStackFunction(t, err, "github.com/pierrre/errors_test.TestNewfWrapVerbIndexed")
+}
+
+func TestNewfWrapVerbFlag(t *testing.T) {
+	errBase := errbase.New("error")
+	err := Newf("test %+w", errBase)
+	assert.ErrorEqual(t, err, "test error")
+	assert.ErrorIs(t, err, errBase)
+}
+
+func TestNewfEscapedWrapVerb(t *testing.T) {
+	err := Newf("test %%w")
+	assert.ErrorEqual(t, err, "test %w")
+	assert.Zero(t, Unwrap(err))
+}
+
 func TestReportGlobalInitPanics(t *testing.T) {
 	assert.Panics(t, func() {
 		ReportGlobalInit.Load()(New("error"))
@@ -81,6 +121,94 @@ func TestWrap(t *testing.T) {
 	assert.SliceLen(t, sfs, 1)
 }
 
+func TestWrapVerbose(t *testing.T) {
+	err := errbase.New("error")
+	err = Wrap(err, "test")
+	s := errverbose.String(err)
+	assert.RegexpMatch(t, `^test: error\nstack:\n(.+\n\t.+:\d+\n)+\n$`, s)
+	checkStackFunction(t, err, "github.com/pierrre/errors_test.TestWrapVerbose")
+}
+
+func TestWrapStack(t *testing.T) {
+	err := New("error")
+	err = Wrap(err, "test")
+	assert.ErrorEqual(t, err, "test: error")
+	checkStackFunction(t, err, "github.com/pierrre/errors_test.TestWrapStack")
+	err = Unwrap(err)
+	assert.ErrorEqual(t, err, "error")
+}
+
+func TestWrapEmptyMessage(t *testing.T) {
+	errBase := errbase.New("error")
+	err := Wrap(errBase, "")
+	assert.ErrorEqual(t, err, "error")
+	checkStackFunction(t, err, "github.com/pierrre/errors_test.TestWrapEmptyMessage")
+	assert.Equal(t, Unwrap(err), errBase)
+}
+
+func enableLazyFormat(tb testing.TB) {
+	tb.Helper()
+	old := errmsg.LazyFormat.Swap(true)
+	tb.Cleanup(func() {
+		errmsg.LazyFormat.Store(old)
+	})
+}
+
+func TestWrapfLazy(t *testing.T) {
+	enableLazyFormat(t)
+	errBase := errbase.New("error")
+	err := Wrapf(errBase, "test %d", 1)
+	assert.ErrorEqual(t, err, "test 1: error")
+	assert.ErrorIs(t, err, errBase)
+	checkStackFunction(t, err, "github.com/pierrre/errors_test.TestWrapfLazy")
+}
+
+func TestWrapfLazyStack(t *testing.T) {
+	enableLazyFormat(t)
+	err := New("error")
+	err = Wrapf(err, "test %d", 1)
+	assert.ErrorEqual(t, err, "test 1: error")
+	checkStackFunction(t, err, "github.com/pierrre/errors_test.TestWrapfLazyStack")
+}
+
+func TestWrapfLazyEmpty(t *testing.T) {
+	enableLazyFormat(t)
+	err := Wrapf(errbase.New("error"), "%s", "")
+	assert.ErrorEqual(t, err, "error")
+}
+
+func TestWrapfEmpty(t *testing.T) {
+	errBase := errbase.New("error")
+	err := Wrapf(errBase, "%s", "")
+	assert.ErrorEqual(t, err, "error")
+	checkStackFunction(t, err, "github.com/pierrre/errors_test.TestWrapfEmpty")
+	assert.Equal(t, Unwrap(err), errBase)
+}
+
+func TestNewfLazy(t *testing.T) {
+	enableLazyFormat(t)
+	err := Newf("error %d", 1)
+	assert.ErrorEqual(t, err, "error 1")
+	checkStackFunction(t, err, "github.com/pierrre/errors_test.TestNewfLazy")
+}
+
+func TestWrapfDeferLazy(t *testing.T) {
+	enableLazyFormat(t)
+	err := testWrapfDefer(errbase.New("error"))
+	assert.ErrorEqual(t, err, "test 1: error")
+	checkStackFunction(t, err, "github.com/pierrre/errors_test.testWrapfDefer")
+}
+
+func TestWrapfLazyAllocs(t *testing.T) {
+	enableLazyFormat(t)
+	err := errbase.New("error")
+	var res error
+	assert.AllocsPerRun(t, 100, func() {
+		res = Wrapf(err, "test %d", 1)
+	}, 2)
+	testSink = res
+}
+
 func TestWrapNil(t *testing.T) {
 	var err error
 	err = Wrap(err, "test")
@@ -101,6 +229,115 @@ func TestWrapfNil(t *testing.T) {
 	assert.Zero(t, err)
 }
 
+func ExampleWrapDefer() {
+	f := func() (err error) {
+		defer WrapDefer(&err, "wrap")
+		return errbase.New("error")
+	}
+	err := f()
+	fmt.Println(err)
+	// Output: wrap: error
+}
+
+func testWrapDefer(err error) (resErr error) {
+	defer WrapDefer(&resErr, "test")
+	return err
+}
+
+func TestWrapDefer(t *testing.T) {
+	err := testWrapDefer(errbase.New("error"))
+	assert.ErrorEqual(t, err, "test: error")
+	checkStackFunction(t, err, "github.com/pierrre/errors_test.testWrapDefer")
+}
+
+func TestWrapDeferNil(t *testing.T) {
+	err := testWrapDefer(nil)
+	assert.NoError(t, err)
+}
+
+func testWrapfDefer(err error) (resErr error) {
+	defer WrapfDefer(&resErr, "test %d", 1)
+	return err
+}
+
+func TestWrapfDefer(t *testing.T) {
+	err := testWrapfDefer(errbase.New("error"))
+	assert.ErrorEqual(t, err, "test 1: error")
+	checkStackFunction(t, err, "github.com/pierrre/errors_test.testWrapfDefer")
+}
+
+func TestWrapfDeferNil(t *testing.T) {
+	err := testWrapfDefer(nil)
+	assert.NoError(t, err)
+}
+
+type testCloser struct {
+	err error
+}
+
+func (c *testCloser) Close() error {
+	return c.err
+}
+
+func testCloseDefer(err error, c io.Closer) (resErr error) {
+	defer CloseDefer(&resErr, c)
+	return err
+}
+
+func TestCloseDefer(t *testing.T) {
+	err := testCloseDefer(nil, &testCloser{err: errbase.New("error")})
+	assert.ErrorEqual(t, err, "close: error")
+	checkStackFunction(t, err, "github.com/pierrre/errors_test.testCloseDefer")
+}
+
+func TestCloseDeferJoin(t *testing.T) {
+	err := testCloseDefer(errbase.New("error 1"), &testCloser{err: errbase.New("error 2")})
+	assert.ErrorEqual(t, err, "error 1\nclose: error 2")
+}
+
+func TestCloseDeferNoError(t *testing.T) {
+	err := testCloseDefer(errbase.New("error"), &testCloser{})
+	assert.ErrorEqual(t, err, "error")
+}
+
+func testCallDefer(err error, f func() error) (resErr error) {
+	defer CallDefer(&resErr, f)
+	return err
+}
+
+func TestCallDefer(t *testing.T) {
+	err := testCallDefer(nil, func() error {
+		return errbase.New("error")
+	})
+	assert.ErrorEqual(t, err, "error")
+	checkStackFunction(t, err, "github.com/pierrre/errors_test.testCallDefer")
+}
+
+func TestCallDeferJoin(t *testing.T) {
+	err := testCallDefer(errbase.New("error 1"), func() error {
+		return errbase.New("error 2")
+	})
+	assert.ErrorEqual(t, err, "error 1\nerror 2")
+	sfs := slices.Collect(errstack.Frames(err))
+	assert.SliceLen(t, sfs, 1)
+}
+
+func TestCallDeferNoError(t *testing.T) {
+	err := testCallDefer(nil, func() error {
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func checkStackFunction(tb testing.TB, err error, function string) {
+	tb.Helper()
+	sfs := slices.Collect(errstack.Frames(err))
+	assert.SliceLen(tb, sfs, 1)
+	fs := slices.Collect(sfs[0])
+	assert.SliceNotEmpty(tb, fs)
+	assert.Equal(tb, fs[0].Function, function)
+}
+
 func TestAs(t *testing.T) {
 	err := errbase.New("error")
 	err = &fs.PathError{Err: err}
@@ -145,15 +382,23 @@ func TestUnwrap(t *testing.T) {
 	errBase := errbase.New("error")
 	err := Wrap(errBase, "test")
 	err = Unwrap(err)
-	err = Unwrap(err)
 	assert.Equal(t, err, errBase)
 }
 
+func TestUnwrapStack(t *testing.T) {
+	errBase := errbase.New("error")
+	err := Wrap(errBase, "test")
+	err = Wrap(err, "other")
+	err = Unwrap(err)
+	assert.ErrorEqual(t, err, "test: error")
+	assert.Equal(t, Unwrap(err), errBase)
+}
+
 func TestNewAllocs(t *testing.T) {
 	var res error
 	assert.AllocsPerRun(t, 100, func() {
 		res = New("error")
-	}, 3)
+	}, 1)
 	testSink = res
 }
 
@@ -161,7 +406,7 @@ func TestNewfAllocs(t *testing.T) {
 	var res error
 	assert.AllocsPerRun(t, 100, func() {
 		res = Newf("error %d", 1)
-	}, 4)
+	}, 2)
 	testSink = res
 }
 
@@ -170,7 +415,7 @@ func TestWrapAllocs(t *testing.T) {
 	var res error
 	assert.AllocsPerRun(t, 100, func() {
 		res = Wrap(err, "test")
-	}, 3)
+	}, 1)
 	testSink = res
 }
 
@@ -179,7 +424,17 @@ func TestWrapfAllocs(t *testing.T) {
 	var res error
 	assert.AllocsPerRun(t, 100, func() {
 		res = Wrapf(err, "test %d", 1)
-	}, 4)
+	}, 2)
+	testSink = res
+}
+
+func TestWrapDeferAllocs(t *testing.T) {
+	err := errbase.New("error")
+	var res error
+	assert.AllocsPerRun(t, 100, func() {
+		res = err
+		WrapDefer(&res, "test")
+	}, 1)
 	testSink = res
 }
 
@@ -189,6 +444,14 @@ func BenchmarkNew(b *testing.B) {
 	}
 }
 
+func BenchmarkNewParallel(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = New("error")
+		}
+	})
+}
+
 func BenchmarkNewf(b *testing.B) {
 	for b.Loop() {
 		_ = Newf("error %d", 1)
@@ -202,9 +465,41 @@ func BenchmarkWrap(b *testing.B) {
 	}
 }
 
+func BenchmarkWrapParallel(b *testing.B) {
+	err := errbase.New("error")
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = Wrap(err, "test")
+		}
+	})
+}
+
 func BenchmarkWrapf(b *testing.B) {
 	err := errbase.New("error")
 	for b.Loop() {
 		_ = Wrapf(err, "test %d", 1)
 	}
 }
+
+func BenchmarkWrapfLazy(b *testing.B) {
+	enableLazyFormat(b)
+	err := errbase.New("error")
+	for b.Loop() {
+		_ = Wrapf(err, "test %d", 1)
+	}
+}
+
+func BenchmarkNewfLazy(b *testing.B) {
+	enableLazyFormat(b)
+	for b.Loop() {
+		_ = Newf("error %d", 1)
+	}
+}
+
+func BenchmarkWrapDefer(b *testing.B) {
+	err := errbase.New("error")
+	for b.Loop() {
+		res := err
+		WrapDefer(&res, "test")
+	}
+}
@@ -0,0 +1,171 @@
+// Package errreport provides renderers of error reports, in Markdown and HTML.
+//
+// A report contains a section for the main error chain, and a section for each sub error (see [errverbose.Write]).
+// It is limited by [errverbose.DefaultLimits].
+// Each section shows:
+//   - the message
+//   - a table of the tags and values
+//   - the stack traces, in collapsible blocks
+//   - the other verbose messages (see [errverbose.AppendVerbose]), so custom errors implementing [errverbose.Interface] are included
+package errreport
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pierrre/errors/erriter"
+	"github.com/pierrre/errors/errverbose"
+	"github.com/pierrre/go-libs/bytesutil"
+)
+
+type section struct {
+	title   string // Empty for the notes of the limits, which only have a marker.
+	message string
+	fields  []field
+	stacks  []string
+	details []string
+	marker  string
+}
+
+type field struct {
+	kind  string
+	key   string
+	value string
+}
+
+var bytesWriterPool = &bytesutil.WriterPool{}
+
+// collectSections collects the sections of the report.
+//
+// [errverbose.Limits.MaxJoinErrors] limits the number of sub errors per joined error.
+// [errverbose.Limits.MaxBytes] limits the total size of the messages and verbose messages, the remaining errors are not collected.
+func collectSections(err error, limits errverbose.Limits) []*section {
+	var sections []*section
+	var s *section
+	size := 0
+	erriter.Walk(err, func(n erriter.Node) erriter.WalkAction {
+		if limits.MaxBytes > 0 && size > limits.MaxBytes {
+			sections = append(sections, &section{
+				marker: "... (truncated)",
+			})
+			return erriter.WalkStop
+		}
+		if n.Depth == 0 && len(n.Path) > 0 && limits.MaxJoinErrors > 0 {
+			i := n.Path[len(n.Path)-1]
+			if i >= limits.MaxJoinErrors {
+				if i == limits.MaxJoinErrors {
+					errs, _ := erriter.Unwrap(n.Parent)
+					sections = append(sections, &section{
+						marker: string(errverbose.AppendMoreErrors(nil, len(errs)-i)),
+					})
+				}
+				return erriter.WalkSkip
+			}
+		}
+		if n.Depth == 0 {
+			s = &section{
+				title: sectionTitle(n.Path),
+			}
+			sections = append(sections, s)
+		}
+		switch {
+		case n.Cycle:
+			s.marker = "<cycle detected>"
+			return erriter.WalkSkip
+		case n.Truncated:
+			s.marker = "<truncated>"
+			return erriter.WalkSkip
+		}
+		if n.Depth == 0 {
+			s.message = string(errverbose.AppendMessageLimits(nil, n.Err, limits))
+			size += len(s.message)
+		}
+		size += addContribution(s, n.Err, limits)
+		return erriter.WalkContinue
+	})
+	return sections
+}
+
+func sectionTitle(path []int) string {
+	if len(path) == 0 {
+		return "Error"
+	}
+	var sb strings.Builder
+	sb.WriteString("Sub error ")
+	for i, d := range path {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(strconv.Itoa(d))
+	}
+	return sb.String()
+}
+
+// addContribution adds the verbose message of the error (see [errverbose.AppendVerboseLimits]) to the section.
+//
+// The verbose messages of the stacks, tags and values are recognized by their prefix, and the other ones are added as details.
+// It returns the size of the verbose message.
+func addContribution(s *section, err error, limits errverbose.Limits) int {
+	bw := bytesWriterPool.Get()
+	defer bytesWriterPool.Put(bw)
+	var ok bool
+	*bw, ok = errverbose.AppendVerboseLimits(*bw, err, limits)
+	if !ok {
+		return 0
+	}
+	verbose := bw.String()
+	switch v := err.(type) { //nolint:errorlint // We want to check for specific error types.
+	case interface{ StackFrames() []uintptr }:
+		stack, ok := strings.CutPrefix(verbose, "stack:\n")
+		if ok {
+			s.stacks = append(s.stacks, strings.TrimSuffix(stack, "\n"))
+			return len(verbose)
+		}
+	case interface{ Tag() (string, string) }:
+		k, _ := v.Tag()
+		if addField(s, "tag", k, verbose) {
+			return len(verbose)
+		}
+	case interface{ Value() (string, any) }:
+		k, _ := v.Value()
+		if addField(s, "value", k, verbose) {
+			return len(verbose)
+		}
+	}
+	s.details = append(s.details, verbose)
+	return len(verbose)
+}
+
+// addField adds a field to the section, if the verbose message is "<kind> <key> = <value>".
+func addField(s *section, kind string, key string, verbose string) bool {
+	value, ok := strings.CutPrefix(verbose, kind+" "+key+" = ")
+	if !ok {
+		return false
+	}
+	s.fields = append(s.fields, field{
+		kind:  kind,
+		key:   key,
+		value: value,
+	})
+	return true
+}
+
+func write(w io.Writer, err error, f func(bw *bytesutil.Writer, err error)) {
+	bw, ok := w.(*bytesutil.Writer)
+	if !ok {
+		bw = bytesWriterPool.Get()
+		defer func() {
+			_, _ = w.Write(*bw)
+			bytesWriterPool.Put(bw)
+		}()
+	}
+	f(bw, err)
+}
+
+func toString(err error, f func(bw *bytesutil.Writer, err error)) string {
+	bw := bytesWriterPool.Get()
+	defer bytesWriterPool.Put(bw)
+	f(bw, err)
+	return bw.String()
+}
@@ -0,0 +1,190 @@
+package errreport_test
+
+import (
+	std_errors "errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pierrre/assert"
+	"github.com/pierrre/errors/errbase"
+	. "github.com/pierrre/errors/errreport"
+	"github.com/pierrre/errors/errstack"
+	"github.com/pierrre/errors/errtag"
+	"github.com/pierrre/errors/errval"
+	"github.com/pierrre/errors/errverbose"
+)
+
+func ExampleMarkdown() {
+	err := errbase.New("error")
+	err = errtag.Wrap(err, "foo", "bar")
+	s := Markdown(err)
+	fmt.Print(s)
+	// Output:
+	// ## Error
+	//
+	// ```text
+	// error
+	// ```
+	//
+	// | Kind | Key | Value |
+	// | --- | --- | --- |
+	// | tag | foo | bar |
+}
+
+type testVerboseError struct {
+	error
+}
+
+func (v *testVerboseError) ErrorVerbose() string {
+	return "verbose <b>"
+}
+
+func (v *testVerboseError) Unwrap() error {
+	return v.error
+}
+
+func newTestError() error {
+	err := errbase.New("error <a>")
+	err = &testVerboseError{
+		error: err,
+	}
+	err = errval.Wrap(err, "k|v", "x")
+	err = errtag.Wrap(err, "foo", "bar\nbaz")
+	return std_errors.Join(err, errbase.New("error ```b```"))
+}
+
+func TestMarkdown(t *testing.T) {
+	err := newTestError()
+	s := Markdown(err)
+	expected := "## Error\n\n" +
+		"````text\nerror <a>\nerror ```b```\n````\n\n" +
+		"## Sub error 0\n\n" +
+		"```text\nerror <a>\n```\n\n" +
+		"| Kind | Key | Value |\n| --- | --- | --- |\n" +
+		"| tag | foo | bar<br>baz |\n" +
+		"| value | k\\|v | \\[string\\] \\(len=1\\) \"x\" |\n\n" +
+		"```text\nverbose <b>\n```\n\n" +
+		"## Sub error 1\n\n" +
+		"````text\nerror ```b```\n````\n\n"
+	assert.Equal(t, s, expected)
+}
+
+func TestMarkdownStack(t *testing.T) {
+	err := errstack.Wrap(errbase.New("error"))
+	s := Markdown(err)
+	assert.StringContains(t, s, "<details>\n<summary>Stack</summary>\n\n```text\ngithub.com/pierrre/errors/errreport_test.TestMarkdownStack\n")
+	assert.StringHasSuffix(t, s, "```\n\n</details>\n\n")
+}
+
+func TestMarkdownMaxValueLength(t *testing.T) {
+	previous := errverbose.DefaultLimits.Swap(errverbose.Limits{MaxValueLength: 10})
+	defer errverbose.DefaultLimits.Store(previous)
+	err := errval.Wrap(errbase.New("error"), "foo", strings.Repeat("a", 100))
+	s := Markdown(err)
+	assert.StringContains(t, s, "| value | foo | \\[string\\] \\(\\.\\.\\. \\(truncated, 121 bytes\\) |\n")
+}
+
+func TestMarkdownCustomTagVerbose(t *testing.T) {
+	err := &testCustomTagError{}
+	s := Markdown(err)
+	assert.Equal(t, s, "## Error\n\n```text\nerror\n```\n\n```text\ncustom tag\n```\n\n")
+}
+
+type testCustomTagError struct{}
+
+func (e *testCustomTagError) Error() string {
+	return "error"
+}
+
+func (e *testCustomTagError) Tag() (string, string) {
+	return "foo", "bar"
+}
+
+func (e *testCustomTagError) ErrorVerbose() string {
+	return "custom tag"
+}
+
+func TestMarkdownMaxJoinErrors(t *testing.T) {
+	previous := errverbose.DefaultLimits.Swap(errverbose.Limits{MaxJoinErrors: 1})
+	defer errverbose.DefaultLimits.Store(previous)
+	err := std_errors.Join(errbase.New("error a"), errbase.New("error b"), errbase.New("error c"))
+	s := Markdown(err)
+	assert.StringHasSuffix(t, s, "## Sub error 0\n\n```text\nerror a\n```\n\n\\.\\.\\. and 2 more errors\n\n")
+	assert.StringNotContains(t, s, "Sub error 1")
+}
+
+func TestMarkdownMaxBytes(t *testing.T) {
+	previous := errverbose.DefaultLimits.Swap(errverbose.Limits{MaxBytes: 10})
+	defer errverbose.DefaultLimits.Store(previous)
+	err := std_errors.Join(errbase.New("error a"), errbase.New("error b"))
+	s := Markdown(err)
+	assert.Equal(t, s, "## Error\n\n```text\nerror a\nerror b\n```\n\n\\.\\.\\. \\(truncated\\)\n\n")
+}
+
+func TestHTMLMaxJoinErrors(t *testing.T) {
+	previous := errverbose.DefaultLimits.Swap(errverbose.Limits{MaxJoinErrors: 1})
+	defer errverbose.DefaultLimits.Store(previous)
+	err := std_errors.Join(errbase.New("error a"), errbase.New("error b"), errbase.New("error c"))
+	s := HTML(err)
+	assert.StringContains(t, s, "<section>\n<p>... and 2 more errors</p>\n</section>\n")
+}
+
+func TestMarkdownNil(t *testing.T) {
+	s := Markdown(nil)
+	assert.Equal(t, s, "\\<nil\\>\n")
+}
+
+func TestMarkdownCycle(t *testing.T) {
+	err := &testCycleError{}
+	s := Markdown(err)
+	assert.Equal(t, s, "## Error\n\n```text\ncycle\n```\n\n\\<cycle detected\\>\n\n")
+}
+
+type testCycleError struct{}
+
+func (e *testCycleError) Error() string {
+	return "cycle"
+}
+
+func (e *testCycleError) Unwrap() error {
+	return e
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	err := newTestError()
+	sb := new(strings.Builder)
+	WriteMarkdown(sb, err)
+	assert.Equal(t, sb.String(), Markdown(err))
+}
+
+func TestHTML(t *testing.T) {
+	err := newTestError()
+	s := HTML(err)
+	assert.StringHasPrefix(t, s, "<!DOCTYPE html>\n")
+	assert.StringHasSuffix(t, s, "</body>\n</html>\n")
+	assert.StringContains(t, s, "<section>\n<h2>Sub error 0</h2>\n<pre class=\"message\">error &lt;a&gt;</pre>\n")
+	assert.StringContains(t, s, "<tr><td>tag</td><td>foo</td><td><pre>bar\nbaz</pre></td></tr>\n")
+	assert.StringContains(t, s, "<tr><td>value</td><td>k|v</td><td><pre>[string] (len=1) &#34;x&#34;</pre></td></tr>\n")
+	assert.StringContains(t, s, "<pre>verbose &lt;b&gt;</pre>\n")
+	assert.StringNotContains(t, s, "<a>")
+	assert.StringNotContains(t, s, "<b>")
+}
+
+func TestHTMLStack(t *testing.T) {
+	err := errstack.Wrap(errbase.New("error"))
+	s := HTML(err)
+	assert.StringContains(t, s, "<details>\n<summary>Stack</summary>\n<pre>github.com/pierrre/errors/errreport_test.TestHTMLStack\n")
+}
+
+func TestHTMLNil(t *testing.T) {
+	s := HTML(nil)
+	assert.StringContains(t, s, "<pre>&lt;nil&gt;</pre>\n")
+}
+
+func TestWriteHTML(t *testing.T) {
+	err := newTestError()
+	sb := new(strings.Builder)
+	WriteHTML(sb, err)
+	assert.Equal(t, sb.String(), HTML(err))
+}
@@ -0,0 +1,102 @@
+package errreport
+
+import (
+	"html"
+	"io"
+
+	"github.com/pierrre/errors/errverbose"
+	"github.com/pierrre/go-libs/bytesutil"
+)
+
+// WriteHTML writes the error report as a self-contained HTML document to the writer.
+//
+// All the texts are escaped.
+// The stack traces are in collapsible <details> blocks, and the tags and values are in a table.
+func WriteHTML(w io.Writer, err error) {
+	write(w, err, writeHTML)
+}
+
+// HTML returns the error report as a self-contained HTML document (see [WriteHTML]).
+func HTML(err error) string {
+	return toString(err, writeHTML)
+}
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Error report</title>
+<style>
+body { font-family: sans-serif; }
+pre { background: #f6f8fa; padding: 8px; overflow-x: auto; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; vertical-align: top; }
+td pre { margin: 0; padding: 0; background: none; }
+.message { color: #cf222e; font-weight: bold; }
+</style>
+</head>
+<body>
+`
+
+const htmlFooter = `</body>
+</html>
+`
+
+func writeHTML(bw *bytesutil.Writer, err error) {
+	bw.AppendString(htmlHeader)
+	if err == nil {
+		bw.AppendString("<pre>&lt;nil&gt;</pre>\n")
+	} else {
+		for _, s := range collectSections(err, errverbose.DefaultLimits.Load()) {
+			writeHTMLSection(bw, s)
+		}
+	}
+	bw.AppendString(htmlFooter)
+}
+
+func writeHTMLSection(bw *bytesutil.Writer, s *section) {
+	bw.AppendString("<section>\n")
+	if s.title != "" {
+		bw.AppendString("<h2>")
+		writeHTMLText(bw, s.title)
+		bw.AppendString("</h2>\n")
+	}
+	if s.message != "" {
+		bw.AppendString(`<pre class="message">`)
+		writeHTMLText(bw, s.message)
+		bw.AppendString("</pre>\n")
+	}
+	if len(s.fields) > 0 {
+		bw.AppendString("<table>\n<tr><th>Kind</th><th>Key</th><th>Value</th></tr>\n")
+		for _, f := range s.fields {
+			bw.AppendString("<tr><td>")
+			writeHTMLText(bw, f.kind)
+			bw.AppendString("</td><td>")
+			writeHTMLText(bw, f.key)
+			bw.AppendString("</td><td><pre>")
+			writeHTMLText(bw, f.value)
+			bw.AppendString("</pre></td></tr>\n")
+		}
+		bw.AppendString("</table>\n")
+	}
+	for _, stack := range s.stacks {
+		bw.AppendString("<details>\n<summary>Stack</summary>\n<pre>")
+		writeHTMLText(bw, stack)
+		bw.AppendString("</pre>\n</details>\n")
+	}
+	for _, d := range s.details {
+		bw.AppendString("<pre>")
+		writeHTMLText(bw, d)
+		bw.AppendString("</pre>\n")
+	}
+	if s.marker != "" {
+		bw.AppendString("<p>")
+		writeHTMLText(bw, s.marker)
+		bw.AppendString("</p>\n")
+	}
+	bw.AppendString("</section>\n")
+}
+
+func writeHTMLText(bw *bytesutil.Writer, s string) {
+	bw.AppendString(html.EscapeString(s))
+}
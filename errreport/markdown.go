@@ -0,0 +1,117 @@
+package errreport
+
+import (
+	"io"
+	"strings"
+
+	"github.com/pierrre/errors/errverbose"
+	"github.com/pierrre/go-libs/bytesutil"
+)
+
+// WriteMarkdown writes the error report in Markdown to the writer.
+//
+// The stack traces are in collapsible <details> blocks, and the tags and values are in a table.
+func WriteMarkdown(w io.Writer, err error) {
+	write(w, err, writeMarkdown)
+}
+
+// Markdown returns the error report in Markdown (see [WriteMarkdown]).
+func Markdown(err error) string {
+	return toString(err, writeMarkdown)
+}
+
+func writeMarkdown(bw *bytesutil.Writer, err error) {
+	if err == nil {
+		writeMarkdownCell(bw, "<nil>")
+		bw.AppendByte('\n')
+		return
+	}
+	for _, s := range collectSections(err, errverbose.DefaultLimits.Load()) {
+		writeMarkdownSection(bw, s)
+	}
+}
+
+func writeMarkdownSection(bw *bytesutil.Writer, s *section) {
+	if s.title != "" {
+		bw.AppendString("## ")
+		bw.AppendString(s.title)
+		bw.AppendString("\n\n")
+	}
+	if s.message != "" {
+		writeMarkdownCode(bw, s.message)
+	}
+	if len(s.fields) > 0 {
+		bw.AppendString("| Kind | Key | Value |\n| --- | --- | --- |\n")
+		for _, f := range s.fields {
+			bw.AppendString("| ")
+			bw.AppendString(f.kind)
+			bw.AppendString(" | ")
+			writeMarkdownCell(bw, f.key)
+			bw.AppendString(" | ")
+			writeMarkdownCell(bw, f.value)
+			bw.AppendString(" |\n")
+		}
+		bw.AppendByte('\n')
+	}
+	for _, stack := range s.stacks {
+		bw.AppendString("<details>\n<summary>Stack</summary>\n\n")
+		writeMarkdownCode(bw, stack)
+		bw.AppendString("</details>\n\n")
+	}
+	for _, d := range s.details {
+		writeMarkdownCode(bw, d)
+	}
+	if s.marker != "" {
+		writeMarkdownCell(bw, s.marker)
+		bw.AppendString("\n\n")
+	}
+}
+
+// writeMarkdownCode writes a fenced code block.
+// The fence is longer than the longest sequence of backticks in s.
+func writeMarkdownCode(bw *bytesutil.Writer, s string) {
+	fence := max(longestBackticks(s)+1, 3)
+	for range fence {
+		bw.AppendByte('`')
+	}
+	bw.AppendString("text\n")
+	bw.AppendString(s)
+	bw.AppendByte('\n')
+	for range fence {
+		bw.AppendByte('`')
+	}
+	bw.AppendString("\n\n")
+}
+
+func longestBackticks(s string) int {
+	longest, current := 0, 0
+	for i := range len(s) {
+		if s[i] == '`' {
+			current++
+			longest = max(longest, current)
+		} else {
+			current = 0
+		}
+	}
+	return longest
+}
+
+// writeMarkdownCell writes inline text, with the Markdown and HTML special characters escaped.
+// Newlines are replaced with <br>, so the text can be used in a table cell.
+func writeMarkdownCell(bw *bytesutil.Writer, s string) {
+	for i := range len(s) {
+		c := s[i]
+		switch {
+		case c == '\n':
+			bw.AppendString("<br>")
+		case c == '\r':
+		case strings.IndexByte(markdownSpecialChars, c) >= 0:
+			bw.AppendByte('\\')
+			bw.AppendByte(c)
+		default:
+			bw.AppendByte(c)
+		}
+	}
+}
+
+const markdownSpecialChars = "\\`*_{}[]()<>#+-.!|~&"
@@ -4,7 +4,7 @@ package errslog
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"iter"
 	"log/slog"
 	"slices"
@@ -12,6 +12,7 @@ import (
 
 	"github.com/pierrre/errors/errappend"
 	"github.com/pierrre/errors/erriter"
+	"github.com/pierrre/errors/errverbose"
 	"github.com/pierrre/go-libs/syncutil"
 )
 
@@ -42,6 +43,10 @@ func (e *attrError) Unwrap() error {
 	return e.error
 }
 
+func (e *attrError) Format(s fmt.State, verb rune) {
+	errverbose.Format(s, verb, e)
+}
+
 func (e *attrError) SlogAttrs() []slog.Attr {
 	return e.attrs
 }
@@ -104,8 +109,13 @@ func appendGroup(b []byte, attrs []slog.Attr) []byte {
 // The order is from the outermost error to the innermost (see [erriter.All]).
 // Duplicates are not removed; use [GetAttrs] to get unique attributes.
 func AllAttrs(err error) iter.Seq[slog.Attr] {
+	return AllAttrsOrder(err, erriter.OrderDepthFirst)
+}
+
+// AllAttrsOrder is like [AllAttrs], but iterates over the error tree in the given [erriter.Order].
+func AllAttrsOrder(err error, order erriter.Order) iter.Seq[slog.Attr] {
 	return func(yield func(slog.Attr) bool) {
-		for err := range erriter.All(err) {
+		for err := range erriter.AllOrder(err, order) {
 			erra, ok := err.(interface{ SlogAttrs() []slog.Attr })
 			if !ok {
 				continue
@@ -122,7 +132,14 @@ func AllAttrs(err error) iter.Seq[slog.Attr] {
 // GetAttrs returns all the attributes of an error tree, recursively, without duplicates.
 // For each key, only the first attribute is kept (see [AllAttrs]).
 func GetAttrs(err error) []slog.Attr {
-	attrs := getAttrs(err)
+	return GetAttrsOrder(err, erriter.OrderDepthFirst)
+}
+
+// GetAttrsOrder is like [GetAttrs], but the first attribute in the given [erriter.Order] is kept for each key.
+//
+// Use [erriter.OrderInnermostFirst] to keep the innermost attribute.
+func GetAttrsOrder(err error, order erriter.Order) []slog.Attr {
+	attrs := getAttrs(err, order)
 	defer releaseAttrsToPool(attrs)
 	var res []slog.Attr
 	if len(attrs) > 0 {
@@ -132,9 +149,9 @@ func GetAttrs(err error) []slog.Attr {
 	return res
 }
 
-func getAttrs(err error) []slog.Attr {
+func getAttrs(err error, order erriter.Order) []slog.Attr {
 	attrs := getAttrsFromPool()
-	for attr := range AllAttrs(err) {
+	for attr := range AllAttrsOrder(err, order) {
 		if !slices.ContainsFunc(attrs, func(a slog.Attr) bool { // The slices is usually small, so it's OK.
 			return a.Key == attr.Key
 		}) {
@@ -177,6 +194,10 @@ func (e *levelError) Unwrap() error {
 	return e.error
 }
 
+func (e *levelError) Format(s fmt.State, verb rune) {
+	errverbose.Format(s, verb, e)
+}
+
 func (e *levelError) ErrorAppend(b []byte) []byte {
 	return errappend.Append(b, e.error)
 }
@@ -194,10 +215,7 @@ func (e *levelError) ErrorVerboseAppend(b []byte) []byte {
 // GetLevel returns the [slog.Level] associated with an error, if it was wrapped with [WrapLevel].
 // The ok boolean indicates whether a level is associated with the error.
 func GetLevel(err error) (l slog.Level, ok bool) {
-	lerr, ok := errors.AsType[interface {
-		error
-		SlogLevel() slog.Level
-	}](err)
+	lerr, ok := erriter.Find[interface{ SlogLevel() slog.Level }](err)
 	if ok {
 		l = lerr.SlogLevel()
 	}
@@ -227,7 +245,7 @@ func LoggerLog(ctx context.Context, logger *slog.Logger, err error, attrs ...slo
 	if !logger.Enabled(ctx, level) {
 		return
 	}
-	errAttrs := getAttrs(err)
+	errAttrs := getAttrs(err, erriter.OrderDepthFirst)
 	defer releaseAttrsToPool(errAttrs)
 	if len(errAttrs) > 0 {
 		if len(attrs) > 0 {
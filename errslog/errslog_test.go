@@ -11,6 +11,7 @@ import (
 	"github.com/pierrre/errors"
 	"github.com/pierrre/errors/errappend"
 	"github.com/pierrre/errors/errbase"
+	"github.com/pierrre/errors/erriter"
 	. "github.com/pierrre/errors/errslog"
 	"github.com/pierrre/errors/errverbose"
 	"github.com/pierrre/go-libs/bytesutil"
@@ -120,6 +121,16 @@ func TestAllAttrsInterrupt(t *testing.T) {
 	}
 }
 
+func TestGetAttrsOrder(t *testing.T) {
+	err := errbase.New("error")
+	err = WrapAttrs(err, slog.Int("int", 1))
+	err = WrapAttrs(err, slog.Int("int", 2), slog.String("string", "test"))
+	attrs := GetAttrsOrder(err, erriter.OrderInnermostFirst)
+	assert.SliceLen(t, attrs, 2)
+	assert.Equal(t, attrs[0].String(), "int=1")
+	assert.Equal(t, attrs[1].String(), "string=test")
+}
+
 func TestGetAttrsAllocs(t *testing.T) {
 	err := errbase.New("error")
 	err = WrapAttrs(err, slog.Int("int", 123), slog.String("string", "test"))
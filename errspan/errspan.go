@@ -0,0 +1,185 @@
+// Package errspan provides utilities to record errors on tracing spans.
+//
+// It doesn't depend on OpenTelemetry: [Span] is a small interface, which can be implemented by an adapter of an OpenTelemetry span (converting [slog.Attr] to attribute.KeyValue), or by a fake span in tests.
+package errspan
+
+import (
+	std_errors "errors"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/pierrre/errors/errbase"
+	"github.com/pierrre/errors/errignore"
+	"github.com/pierrre/errors/erriter"
+	"github.com/pierrre/errors/errslog"
+	"github.com/pierrre/errors/errstack"
+	"github.com/pierrre/errors/errtag"
+	"github.com/pierrre/errors/errtmp"
+	"github.com/pierrre/go-libs/runtimeutil"
+)
+
+// Span is the subset of a tracing span used to record errors.
+//
+// It is not implemented by OpenTelemetry spans: the methods have the same names as the trace.Span methods, but they use [slog.Attr] and [StatusCode].
+// An adapter is required to convert them to attribute.KeyValue and codes.Code, and to call the trace.Span methods.
+type Span interface {
+	// RecordError records an "exception" event with the given attributes.
+	RecordError(err error, attrs ...slog.Attr)
+	// SetAttributes sets attributes on the span.
+	SetAttributes(attrs ...slog.Attr)
+	// SetStatus sets the status of the span.
+	SetStatus(code StatusCode, description string)
+}
+
+// StatusCode is the status code of a span.
+//
+// The values are the same as the OpenTelemetry codes.
+type StatusCode uint32
+
+// Status codes.
+const (
+	StatusUnset StatusCode = 0
+	StatusError StatusCode = 1
+	StatusOK    StatusCode = 2
+)
+
+// Attribute keys, from the OpenTelemetry semantic conventions.
+const (
+	ExceptionTypeKey       = "exception.type"
+	ExceptionMessageKey    = "exception.message"
+	ExceptionStacktraceKey = "exception.stacktrace"
+)
+
+// Attribute keys of the error classification.
+const (
+	ErrorIgnoredKey   = "error.ignored"
+	ErrorTemporaryKey = "error.temporary"
+)
+
+// Record records an error on a span.
+//
+// It does nothing if the error is nil.
+//
+// It records an "exception" event (see [Span.RecordError]) with:
+//   - [ExceptionTypeKey]: the type of the cause of the error (see [erriter.Cause]), the wrappers are not relevant; the internal types of this module are replaced with the equivalent std types (*errors.errorString or *errors.joinError)
+//   - [ExceptionMessageKey]: the error message
+//   - [ExceptionStacktraceKey]: the outermost stack (see [errstack.Frames]), if there is one
+//
+// It sets the tags (see [errtag.Get]) and the slog attributes (see [errslog.GetAttrs]) as span attributes.
+// The attributes of the groups are flattened, with the keys joined by ".".
+//
+// The span status depends on the classification of the error:
+//   - ignored (see [errignore.Is]): the status is not set, and the [ErrorIgnoredKey] attribute is true (the exception event and the other attributes are still recorded)
+//   - otherwise: the status is [StatusError] with the error message, and the [ErrorTemporaryKey] attribute is the result of [errtmp.Is]
+func Record(span Span, err error) {
+	if err == nil {
+		return
+	}
+	msg := err.Error()
+	eventAttrs := []slog.Attr{
+		slog.String(ExceptionTypeKey, exceptionType(err)),
+		slog.String(ExceptionMessageKey, msg),
+	}
+	st, ok := stacktrace(err)
+	if ok {
+		eventAttrs = append(eventAttrs, slog.String(ExceptionStacktraceKey, st))
+	}
+	span.RecordError(err, eventAttrs...)
+	attrs := Attributes(err)
+	if errignore.Is(err) {
+		attrs = append(attrs, slog.Bool(ErrorIgnoredKey, true))
+		span.SetAttributes(attrs...)
+		return
+	}
+	attrs = append(attrs, slog.Bool(ErrorTemporaryKey, errtmp.Is(err)))
+	span.SetAttributes(attrs...)
+	span.SetStatus(StatusError, msg)
+}
+
+// Attributes returns the span attributes of an error: the tags (see [errtag.Get], sorted by key) followed by the flattened slog attributes (see [errslog.GetAttrs]).
+func Attributes(err error) []slog.Attr {
+	tags := errtag.Get(err)
+	var attrs []slog.Attr
+	for _, k := range slices.Sorted(maps.Keys(tags)) {
+		attrs = append(attrs, slog.String(k, tags[k]))
+	}
+	for _, attr := range errslog.GetAttrs(err) {
+		attrs = appendFlatAttr(attrs, "", attr)
+	}
+	return attrs
+}
+
+func appendFlatAttr(attrs []slog.Attr, prefix string, attr slog.Attr) []slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	if attr.Value.Kind() != slog.KindGroup {
+		attr.Key = prefix + attr.Key
+		return append(attrs, attr)
+	}
+	if attr.Key != "" {
+		prefix += attr.Key + "."
+	}
+	for _, a := range attr.Value.Group() {
+		attrs = appendFlatAttr(attrs, prefix, a)
+	}
+	return attrs
+}
+
+// messageType is the type of the errors created by [errbase.New].
+var messageType = typeString(errbase.New(""))
+
+// joinType is the type of the errors created by [std_errors.Join].
+var joinType = typeString(std_errors.Join(errbase.New(""), errbase.New("")))
+
+// exceptionType returns the type of the cause of the error.
+//
+// The types of the errors created by this module are internal, so they are replaced with the types of the equivalent std errors:
+// the type of the errors created by [errbase.New] for an error that doesn't wrap any error (e.g. created by errors.New), and the type of the errors created by [std_errors.Join] for an error that wraps several errors (e.g. created by errjoin.Join).
+func exceptionType(err error) string {
+	cause := erriter.Cause(err)
+	if !isModuleType(cause) {
+		return typeString(cause)
+	}
+	errs, _ := erriter.Unwrap(cause)
+	if len(errs) > 0 {
+		return joinType
+	}
+	return messageType
+}
+
+const modulePath = "github.com/pierrre/errors"
+
+// isModuleType returns true if the type of the error is defined by a (non-test) package of this module.
+func isModuleType(err error) bool {
+	t := reflect.TypeOf(err)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	p := t.PkgPath()
+	if strings.HasSuffix(p, "_test") {
+		return false
+	}
+	return p == modulePath || strings.HasPrefix(p, modulePath+"/")
+}
+
+// typeString returns the type name of the error, like the OpenTelemetry SDK.
+func typeString(err error) string {
+	t := reflect.TypeOf(err)
+	if t.PkgPath() == "" && t.Name() == "" {
+		return t.String()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+func stacktrace(err error) (string, bool) {
+	for fs := range errstack.Frames(err) {
+		var b []byte
+		for f := range fs {
+			b = runtimeutil.AppendFrame(b, f)
+		}
+		return strings.TrimSuffix(string(b), "\n"), true
+	}
+	return "", false
+}
@@ -0,0 +1,160 @@
+package errspan_test
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"testing"
+
+	"github.com/pierrre/assert"
+	"github.com/pierrre/errors"
+	"github.com/pierrre/errors/errbase"
+	"github.com/pierrre/errors/errignore"
+	"github.com/pierrre/errors/errjoin"
+	"github.com/pierrre/errors/errslog"
+	. "github.com/pierrre/errors/errspan"
+	"github.com/pierrre/errors/errtag"
+	"github.com/pierrre/errors/errtmp"
+)
+
+type testSpan struct {
+	err         error
+	eventAttrs  []slog.Attr
+	attrs       []slog.Attr
+	status      StatusCode
+	description string
+}
+
+func (s *testSpan) RecordError(err error, attrs ...slog.Attr) {
+	s.err = err
+	s.eventAttrs = attrs
+}
+
+func (s *testSpan) SetAttributes(attrs ...slog.Attr) {
+	s.attrs = append(s.attrs, attrs...)
+}
+
+func (s *testSpan) SetStatus(code StatusCode, description string) {
+	s.status = code
+	s.description = description
+}
+
+func getAttr(attrs []slog.Attr, key string) (slog.Value, bool) {
+	for _, a := range attrs {
+		if a.Key == key {
+			return a.Value, true
+		}
+	}
+	return slog.Value{}, false
+}
+
+func Example() {
+	span := new(testSpan)
+	err := errbase.New("error")
+	err = errtag.Wrap(err, "user", "123")
+	err = errtmp.Wrap(err, false)
+	Record(span, err)
+	fmt.Println(span.status, span.description)
+	fmt.Println(span.attrs)
+	// Output:
+	// 1 error
+	// [user=123 error.temporary=false]
+}
+
+func TestRecord(t *testing.T) {
+	span := new(testSpan)
+	err := errbase.New("error")
+	err = &fs.PathError{Op: "open", Path: "file", Err: err}
+	err = errors.Wrap(err, "test")
+	err = errtag.Wrap(err, "b", "2")
+	err = errtag.Wrap(err, "a", "1")
+	err = errslog.WrapAttrs(err, slog.Int("c", 3), slog.Group("g", slog.String("d", "4")))
+	Record(span, err)
+	assert.Equal(t, span.err, err)
+	v, ok := getAttr(span.eventAttrs, ExceptionTypeKey)
+	assert.True(t, ok)
+	assert.Equal(t, v.String(), "*errors.errorString")
+	v, ok = getAttr(span.eventAttrs, ExceptionMessageKey)
+	assert.True(t, ok)
+	assert.Equal(t, v.String(), `c=3, g=[d="4"]: test: open file: error`)
+	v, ok = getAttr(span.eventAttrs, ExceptionStacktraceKey)
+	assert.True(t, ok)
+	assert.StringHasPrefix(t, v.String(), "github.com/pierrre/errors/errspan_test.TestRecord\n\t")
+	assert.Equal(t, fmt.Sprint(span.attrs), "[a=1 b=2 c=3 g.d=4 error.temporary=true]")
+	assert.Equal(t, span.status, StatusError)
+	assert.Equal(t, span.description, `c=3, g=[d="4"]: test: open file: error`)
+}
+
+func TestRecordNew(t *testing.T) {
+	span := new(testSpan)
+	err := errors.New("error")
+	err = errors.Wrap(err, "test")
+	Record(span, err)
+	v, ok := getAttr(span.eventAttrs, ExceptionTypeKey)
+	assert.True(t, ok)
+	assert.Equal(t, v.String(), "*errors.errorString")
+}
+
+func TestRecordCustomType(t *testing.T) {
+	span := new(testSpan)
+	err := errors.Newf("test: %w", new(testError))
+	err = errtag.Wrap(err, "a", "1")
+	Record(span, err)
+	v, ok := getAttr(span.eventAttrs, ExceptionTypeKey)
+	assert.True(t, ok)
+	assert.Equal(t, v.String(), "*errspan_test.testError")
+}
+
+type testError struct{}
+
+func (*testError) Error() string {
+	return "error"
+}
+
+func TestRecordNil(t *testing.T) {
+	span := new(testSpan)
+	Record(span, nil)
+	assert.Zero(t, span.err)
+	assert.Equal(t, span.status, StatusUnset)
+}
+
+func TestRecordNoStack(t *testing.T) {
+	span := new(testSpan)
+	Record(span, errbase.New("error"))
+	_, ok := getAttr(span.eventAttrs, ExceptionStacktraceKey)
+	assert.False(t, ok)
+}
+
+func TestRecordIgnored(t *testing.T) {
+	span := new(testSpan)
+	err := errignore.Wrap(errbase.New("error"))
+	Record(span, err)
+	assert.Equal(t, span.err, err)
+	assert.Equal(t, span.status, StatusUnset)
+	v, ok := getAttr(span.attrs, ErrorIgnoredKey)
+	assert.True(t, ok)
+	assert.True(t, v.Bool())
+	_, ok = getAttr(span.attrs, ErrorTemporaryKey)
+	assert.False(t, ok)
+	_, ok = getAttr(span.eventAttrs, ExceptionTypeKey)
+	assert.True(t, ok)
+}
+
+func TestRecordJoin(t *testing.T) {
+	span := new(testSpan)
+	err := errjoin.Join(&fs.PathError{Op: "open", Path: "file", Err: errbase.New("error")}, errbase.New("error"))
+	err = errtag.Wrap(err, "a", "1")
+	Record(span, err)
+	v, ok := getAttr(span.eventAttrs, ExceptionTypeKey)
+	assert.True(t, ok)
+	assert.Equal(t, v.String(), "*errors.joinError")
+}
+
+func TestAttributes(t *testing.T) {
+	err := errbase.New("error")
+	attrs := Attributes(err)
+	assert.SliceEmpty(t, attrs)
+	err = errslog.WrapAttrs(err, slog.Group("", slog.String("a", "1")))
+	attrs = Attributes(err)
+	assert.Equal(t, fmt.Sprint(attrs), "[a=1]")
+}
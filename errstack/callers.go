@@ -0,0 +1,100 @@
+package errstack
+
+import (
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/pierrre/go-libs/runtimeutil"
+	"github.com/pierrre/go-libs/syncutil/atomicutil"
+)
+
+// InlineCallers is the maximum number of callers stored inline in a stack error, without an additional allocation.
+//
+// Deeper stacks are stored in a separate heap allocated slice.
+const InlineCallers = 32
+
+// CaptureCallers captures the callers into buf, skipping the given number of frames.
+//
+// If the stack is interned (see [DefaultInterner]), or if it is deeper than [InlineCallers], it returns a non-nil shared slice, which must not be modified.
+// Otherwise it returns the number of callers written to buf.
+//
+// It allows other error types to store the stack inline, like [Wrap].
+func CaptureCallers(skip int, buf *[InlineCallers]uintptr) (n int, shared []uintptr) {
+	n = runtime.Callers(skip+2, buf[:]) // Skip [CaptureCallers] and [runtime.Callers].
+	in := DefaultInterner.Load()
+	if n >= len(buf) {
+		shared = runtimeutil.GetCallers(skip + 1)
+		if in != nil {
+			shared = in.Intern(shared)
+		}
+		return n, shared
+	}
+	if in != nil {
+		shared = in.Intern(buf[:n])
+	}
+	return n, shared
+}
+
+// DefaultInterner is the [Interner] used to intern the stacks captured by [Wrap].
+//
+// The default value is nil, so the stacks are not interned.
+var DefaultInterner atomicutil.Value[*Interner]
+
+const internerShards = 64
+
+// Interner is a bounded concurrent cache of stacks.
+//
+// Identical stacks (same PCs) share the same callers slice, which reduces the memory usage of hot error paths.
+type Interner struct {
+	shards      [internerShards]internerShard
+	maxPerShard int
+}
+
+type internerShard struct {
+	mu sync.RWMutex
+	m  map[uint64][]uintptr
+}
+
+// NewInterner returns a new [Interner] that contains at most size stacks.
+func NewInterner(size int) *Interner {
+	return &Interner{
+		maxPerShard: max(size/internerShards, 1),
+	}
+}
+
+// Intern returns a shared slice equal to callers.
+//
+// The returned slice must not be modified.
+// callers is not retained.
+func (in *Interner) Intern(callers []uintptr) []uintptr {
+	h := hashCallers(callers)
+	s := &in.shards[h%internerShards]
+	s.mu.RLock()
+	shared, ok := s.m[h]
+	s.mu.RUnlock()
+	if ok && slices.Equal(shared, callers) {
+		return shared
+	}
+	shared = slices.Clone(callers)
+	s.mu.Lock()
+	if s.m == nil {
+		s.m = make(map[uint64][]uintptr)
+	}
+	if len(s.m) >= in.maxPerShard {
+		clear(s.m)
+	}
+	s.m[h] = shared // A colliding stack is replaced.
+	s.mu.Unlock()
+	return shared
+}
+
+// hashCallers computes a FNV-1a hash.
+func hashCallers(callers []uintptr) uint64 {
+	h := uint64(14695981039346656037)
+	for _, pc := range callers {
+		h ^= uint64(pc)
+		h *= 1099511628211
+	}
+	return h
+}
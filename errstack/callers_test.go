@@ -0,0 +1,147 @@
+package errstack_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pierrre/assert"
+	"github.com/pierrre/errors/errbase"
+	. "github.com/pierrre/errors/errstack"
+)
+
+func wrapDeep(err error, depth int) error {
+	if depth == 0 {
+		return Wrap(err)
+	}
+	return wrapDeep(err, depth-1)
+}
+
+func getCallers(tb testing.TB, err error) []uintptr {
+	tb.Helper()
+	errf, _ := assert.ErrorAsType[interface {
+		error
+		StackFrames() []uintptr
+	}](tb, err)
+	return errf.StackFrames()
+}
+
+func TestWrapDeep(t *testing.T) {
+	err := wrapDeep(errbase.New("error"), InlineCallers*2)
+	callers := getCallers(t, err)
+	assert.Greater(t, len(callers), InlineCallers*2)
+	sfs := slices.Collect(Frames(err))
+	assert.SliceLen(t, sfs, 1)
+	fs := slices.Collect(sfs[0])
+	assert.Equal(t, fs[0].Function, "github.com/pierrre/errors/errstack_test.wrapDeep")
+}
+
+func TestCaptureCallers(t *testing.T) {
+	var buf [InlineCallers]uintptr
+	n, shared := CaptureCallers(0, &buf)
+	assert.SliceNil(t, shared)
+	assert.Greater(t, n, 0)
+	assert.Less(t, n, InlineCallers)
+}
+
+func TestCaptureCallersAllocs(t *testing.T) {
+	var buf [InlineCallers]uintptr
+	var res []uintptr
+	assert.AllocsPerRun(t, 100, func() {
+		_, res = CaptureCallers(0, &buf)
+	}, 0)
+	testSink = res
+}
+
+func setDefaultInterner(tb testing.TB, in *Interner) {
+	tb.Helper()
+	old := DefaultInterner.Swap(in)
+	tb.Cleanup(func() {
+		DefaultInterner.Store(old)
+	})
+}
+
+func TestWrapInterned(t *testing.T) {
+	setDefaultInterner(t, NewInterner(100))
+	var errs []error
+	for range 2 {
+		errs = append(errs, Wrap(errbase.New("error")))
+	}
+	errs = append(errs, Wrap(errbase.New("error")))
+	c0 := getCallers(t, errs[0])
+	c1 := getCallers(t, errs[1])
+	c2 := getCallers(t, errs[2])
+	assert.Equal(t, &c0[0], &c1[0])
+	assert.NotEqual(t, &c0[0], &c2[0])
+	assert.SliceNotEqual(t, c0, c2)
+}
+
+func TestWrapDeepInterned(t *testing.T) {
+	setDefaultInterner(t, NewInterner(100))
+	var errs []error
+	for range 2 {
+		errs = append(errs, wrapDeep(errbase.New("error"), InlineCallers*2))
+	}
+	c0 := getCallers(t, errs[0])
+	c1 := getCallers(t, errs[1])
+	assert.Equal(t, &c0[0], &c1[0])
+}
+
+func TestInterner(t *testing.T) {
+	in := NewInterner(0)
+	a := []uintptr{1, 2, 3}
+	s1 := in.Intern(a)
+	assert.SliceEqual(t, s1, a)
+	assert.NotEqual(t, &s1[0], &a[0])
+	s2 := in.Intern(slices.Clone(a))
+	assert.Equal(t, &s1[0], &s2[0])
+	for i := range uintptr(1000) {
+		in.Intern([]uintptr{i})
+	}
+	s3 := in.Intern(a)
+	assert.SliceEqual(t, s3, a)
+}
+
+func TestInternAllocs(t *testing.T) {
+	in := NewInterner(100)
+	a := []uintptr{1, 2, 3}
+	in.Intern(a)
+	var res []uintptr
+	assert.AllocsPerRun(t, 100, func() {
+		res = in.Intern(a)
+	}, 0)
+	testSink = res
+}
+
+func BenchmarkWrapParallel(b *testing.B) {
+	err := errbase.New("error")
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = Wrap(err)
+		}
+	})
+}
+
+func BenchmarkWrapInterned(b *testing.B) {
+	setDefaultInterner(b, NewInterner(1000))
+	err := errbase.New("error")
+	for b.Loop() {
+		_ = Wrap(err)
+	}
+}
+
+func BenchmarkWrapInternedParallel(b *testing.B) {
+	setDefaultInterner(b, NewInterner(1000))
+	err := errbase.New("error")
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = Wrap(err)
+		}
+	})
+}
+
+func BenchmarkWrapDeep(b *testing.B) {
+	err := errbase.New("error")
+	for b.Loop() {
+		_ = wrapDeep(err, InlineCallers*2)
+	}
+}
@@ -2,11 +2,13 @@
 package errstack
 
 import (
+	"fmt"
 	"iter"
 	"runtime"
 
 	"github.com/pierrre/errors/errappend"
 	"github.com/pierrre/errors/erriter"
+	"github.com/pierrre/errors/errverbose"
 	"github.com/pierrre/go-libs/runtimeutil"
 )
 
@@ -24,10 +26,22 @@ func WrapSkip(err error, skip int) error {
 	if err == nil {
 		return nil
 	}
-	return &stack{
+	var buf [InlineCallers]uintptr
+	n, shared := CaptureCallers(skip+1, &buf)
+	if shared != nil {
+		return &stack{
+			error:   err,
+			callers: shared,
+		}
+	}
+	s := &inlineStack{
+		buf: buf,
+	}
+	s.stack = stack{
 		error:   err,
-		callers: runtimeutil.GetCallers(skip + 1),
+		callers: s.buf[:n:n],
 	}
+	return &s.stack
 }
 
 // Ensure adds a stack to an error if it does not already have one.
@@ -48,20 +62,67 @@ type stack struct {
 	callers []uintptr
 }
 
+// inlineStack stores the callers inline, so a single allocation is needed.
+type inlineStack struct {
+	stack
+	buf [InlineCallers]uintptr
+}
+
 func (err *stack) Unwrap() error {
 	return err.error
 }
 
+func (err *stack) Format(s fmt.State, verb rune) {
+	errverbose.Format(s, verb, err)
+}
+
 func (err *stack) ErrorAppend(b []byte) []byte {
 	return errappend.Append(b, err.error)
 }
 
 func (err *stack) ErrorVerboseAppend(b []byte) []byte {
+	return err.ErrorVerboseAppendLimits(b, errverbose.DefaultLimits.Load())
+}
+
+func (err *stack) ErrorVerboseAppendLimits(b []byte, limits errverbose.Limits) []byte {
+	return AppendVerbose(b, err.callers, limits)
+}
+
+// AppendVerbose appends the verbose message of a stack to b: "stack:\n" followed by the frames of the callers.
+//
+// It respects [errverbose.Limits.MaxStackFrames] and [VerboseSource], and uses [DefaultFrameCache].
+//
+// It allows other error types carrying a stack to produce the same verbose message as [Wrap].
+func AppendVerbose(b []byte, callers []uintptr, limits errverbose.Limits) []byte {
 	b = append(b, "stack:\n"...)
-	b = runtimeutil.AppendCallersFrames(b, err.callers)
+	sr := VerboseSource.Load()
+	count := 0
+	for f := range CallersFrames(callers) {
+		count++
+		if limits.MaxStackFrames > 0 && count > limits.MaxStackFrames {
+			continue
+		}
+		b = runtimeutil.AppendFrame(b, f)
+		if sr != nil {
+			b = sr.AppendLines(b, f)
+		}
+	}
+	if limits.MaxStackFrames > 0 && count > limits.MaxStackFrames {
+		b = appendMoreFrames(b, count-limits.MaxStackFrames)
+	}
 	return b
 }
 
+// appendMoreFrames appends "... and N more frames" to b, like [errverbose.AppendMoreErrors].
+func appendMoreFrames(b []byte, n int) []byte {
+	b = append(b, "... and "...)
+	b = errverbose.AppendCount(b, n)
+	if n == 1 {
+		return append(b, " more frame\n"...)
+	}
+	return append(b, " more frames\n"...)
+}
+
 // StackFrames returns the list of PCs associated with the error.
 //
 // It exists and is named StackFrames in order to be compatible with the Sentry library, which expects this name.
@@ -79,7 +140,7 @@ func Frames(err error) iter.Seq[iter.Seq[runtime.Frame]] {
 				StackFrames() []uintptr
 			})
 			if ok {
-				fs := runtimeutil.GetCallersFrames(errf.StackFrames())
+				fs := CallersFrames(errf.StackFrames())
 				if !yield(fs) {
 					return
 				}
@@ -5,6 +5,7 @@ import (
 	"iter"
 	"runtime"
 	"slices"
+	"strconv"
 	"testing"
 
 	"github.com/pierrre/assert"
@@ -96,12 +97,28 @@ func TestJoin(t *testing.T) {
 	assert.SliceLen(t, sfs, 4)
 }
 
+type testCycleError struct{}
+
+func (e *testCycleError) Error() string {
+	return "cycle"
+}
+
+func (e *testCycleError) Unwrap() error {
+	return e
+}
+
+func TestEnsureCycle(t *testing.T) {
+	err := Ensure(&testCycleError{})
+	sfs := slices.Collect(Frames(err))
+	assert.SliceLen(t, sfs, 1)
+}
+
 func TestWrapAllocs(t *testing.T) {
 	err := errbase.New("error")
 	var res error
 	assert.AllocsPerRun(t, 100, func() {
 		res = Wrap(err)
-	}, 2)
+	}, 1)
 	testSink = res
 }
 
@@ -141,7 +158,7 @@ func TestVerboseAllocs(t *testing.T) {
 	assert.AllocsPerRun(t, 100, func() {
 		b = v.ErrorVerboseAppend(b)
 		b = b[:0]
-	}, 1)
+	}, 0)
 }
 
 func BenchmarkWrap(b *testing.B) {
@@ -177,3 +194,17 @@ func BenchmarkVerbose(b *testing.B) {
 		buf = buf[:0]
 	}
 }
+
+func TestVerboseMaxStackFrames(t *testing.T) {
+	err := errbase.New("error")
+	err = Wrap(err)
+	v, _ := assert.ErrorAsType[errverbose.LimitsAppendInterface](t, err)
+	b := v.ErrorVerboseAppendLimits(nil, errverbose.Limits{MaxStackFrames: 1})
+	count := 0
+	for sf := range Frames(err) {
+		for range sf {
+			count++
+		}
+	}
+	assert.RegexpMatch(t, `^stack:\n.+TestVerboseMaxStackFrames\n\t.+:\d+\n\.\.\. and `+strconv.Itoa(count-1)+` more frames?\n$`, string(b))
+}
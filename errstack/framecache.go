@@ -0,0 +1,172 @@
+package errstack
+
+import (
+	"iter"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pierrre/go-libs/runtimeutil"
+	"github.com/pierrre/go-libs/syncutil/atomicutil"
+)
+
+// CallersFrames returns the [runtime.Frame] of the callers.
+//
+// It is the same as [runtimeutil.GetCallersFrames], but the frames are resolved with [DefaultFrameCache].
+func CallersFrames(callers []uintptr) iter.Seq[runtime.Frame] {
+	return func(yield func(runtime.Frame) bool) {
+		fc := DefaultFrameCache.Load()
+		if fc == nil {
+			runtimeutil.GetCallersFrames(callers)(yield)
+			return
+		}
+		fc.callersFrames(callers, yield)
+	}
+}
+
+// DefaultFrameCache is the [FrameCache] used by [CallersFrames], the verbose message of the stacks and [Frames].
+//
+// The default value contains at most 10000 PCs.
+// Set it to nil to disable the cache.
+var DefaultFrameCache atomicutil.Value[*FrameCache]
+
+func init() {
+	DefaultFrameCache.Store(NewFrameCache(10000))
+}
+
+const frameCacheShards = 64
+
+// FrameCache is a bounded concurrent cache of the [runtime.Frame] resolved from a PC.
+//
+// Resolving a PC with [runtime.CallersFrames] is costly, and the same stacks are usually rendered many times (e.g. the same error logged repeatedly).
+type FrameCache struct {
+	shards      [frameCacheShards]frameCacheShard
+	maxPerShard int
+	hits        atomic.Int64
+	misses      atomic.Int64
+}
+
+type frameCacheShard struct {
+	mu sync.RWMutex
+	m  map[uintptr][]runtime.Frame
+}
+
+// NewFrameCache returns a new [FrameCache] that contains at most size PCs.
+func NewFrameCache(size int) *FrameCache {
+	return &FrameCache{
+		maxPerShard: max(size/frameCacheShards, 1),
+	}
+}
+
+// Frames returns the frames resolved from a PC returned by [runtime.Callers].
+//
+// There can be several frames if functions were inlined.
+// The returned slice must not be modified.
+func (fc *FrameCache) Frames(pc uintptr) []runtime.Frame {
+	s := &fc.shards[(uint64(pc)*0x9e3779b97f4a7c15)>>58] // Fibonacci hashing to 6 bits (64 shards).
+	s.mu.RLock()
+	fs, ok := s.m[pc]
+	s.mu.RUnlock()
+	if ok {
+		fc.hits.Add(1)
+		return fs
+	}
+	fc.misses.Add(1)
+	fs = resolveFrames(pc)
+	s.mu.Lock()
+	if s.m == nil {
+		s.m = make(map[uintptr][]runtime.Frame)
+	}
+	if len(s.m) >= fc.maxPerShard {
+		clear(s.m)
+	}
+	s.m[pc] = fs
+	s.mu.Unlock()
+	return fs
+}
+
+func resolveFrames(pc uintptr) []runtime.Frame {
+	var fs []runtime.Frame
+	frames := runtime.CallersFrames([]uintptr{pc})
+	for {
+		f, more := frames.Next()
+		fs = append(fs, f)
+		if !more {
+			return fs
+		}
+	}
+}
+
+func (fc *FrameCache) callersFrames(callers []uintptr, yield func(runtime.Frame) bool) {
+	count := 0
+	for _, pc := range callers {
+		for _, f := range fc.Frames(pc) {
+			if !yield(f) {
+				return
+			}
+			count++
+			if f.Function == "runtime.sigpanic" {
+				// The PC following a panic is not a return address, so it can't be resolved alone.
+				// The remaining frames are resolved without the cache.
+				for f := range runtimeutil.GetCallersFrames(callers) {
+					if count > 0 {
+						count--
+						continue
+					}
+					if !yield(f) {
+						return
+					}
+				}
+				return
+			}
+		}
+	}
+}
+
+// Clear removes all the PCs from the cache, and resets the statistics.
+func (fc *FrameCache) Clear() {
+	for i := range fc.shards {
+		s := &fc.shards[i]
+		s.mu.Lock()
+		clear(s.m)
+		s.mu.Unlock()
+	}
+	fc.hits.Store(0)
+	fc.misses.Store(0)
+}
+
+// Stats returns the statistics of the cache.
+func (fc *FrameCache) Stats() FrameCacheStats {
+	st := FrameCacheStats{
+		Hits:   fc.hits.Load(),
+		Misses: fc.misses.Load(),
+	}
+	for i := range fc.shards {
+		s := &fc.shards[i]
+		s.mu.RLock()
+		st.Size += len(s.m)
+		s.mu.RUnlock()
+	}
+	return st
+}
+
+// FrameCacheStats contains the statistics of a [FrameCache].
+type FrameCacheStats struct {
+	// Hits is the number of PCs found in the cache.
+	Hits int64
+	// Misses is the number of PCs resolved with [runtime.CallersFrames].
+	Misses int64
+	// Size is the number of PCs in the cache.
+	Size int
+}
+
+// HitRate returns the ratio of hits, between 0 and 1.
+//
+// It returns 0 if the cache was never used.
+func (st FrameCacheStats) HitRate() float64 {
+	total := st.Hits + st.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(st.Hits) / float64(total)
+}
@@ -0,0 +1,148 @@
+package errstack_test
+
+import (
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/pierrre/assert"
+	"github.com/pierrre/errors/errbase"
+	. "github.com/pierrre/errors/errstack"
+	"github.com/pierrre/go-libs/runtimeutil"
+)
+
+func setDefaultFrameCache(tb testing.TB, fc *FrameCache) {
+	tb.Helper()
+	old := DefaultFrameCache.Swap(fc)
+	tb.Cleanup(func() {
+		DefaultFrameCache.Store(old)
+	})
+}
+
+func checkCallersFrames(tb testing.TB, callers []uintptr) {
+	tb.Helper()
+	expected := slices.Collect(runtimeutil.GetCallersFrames(callers))
+	for range 2 { // Miss, then hit.
+		fs := slices.Collect(CallersFrames(callers))
+		assert.DeepEqual(tb, fs, expected)
+	}
+}
+
+func TestCallersFrames(t *testing.T) {
+	setDefaultFrameCache(t, NewFrameCache(100))
+	err := Wrap(errbase.New("error"))
+	checkCallersFrames(t, getCallers(t, err))
+}
+
+func TestCallersFramesDeep(t *testing.T) {
+	setDefaultFrameCache(t, NewFrameCache(100))
+	err := wrapDeep(errbase.New("error"), InlineCallers*2)
+	checkCallersFrames(t, getCallers(t, err))
+}
+
+func TestCallersFramesPanic(t *testing.T) {
+	setDefaultFrameCache(t, NewFrameCache(100))
+	var callers []uintptr
+	func() {
+		defer func() {
+			_ = recover()
+			callers = runtimeutil.GetCallers(0)
+		}()
+		var p *int
+		_ = *p //nolint:govet // Trigger a nil pointer dereference.
+	}()
+	fs := slices.Collect(CallersFrames(callers))
+	assert.True(t, slices.ContainsFunc(fs, func(f runtime.Frame) bool {
+		return f.Function == "runtime.sigpanic"
+	}))
+	checkCallersFrames(t, callers)
+}
+
+func TestCallersFramesInterrupt(t *testing.T) {
+	setDefaultFrameCache(t, NewFrameCache(100))
+	err := Wrap(errbase.New("error"))
+	for range CallersFrames(getCallers(t, err)) {
+		break
+	}
+}
+
+func TestCallersFramesDisabled(t *testing.T) {
+	setDefaultFrameCache(t, nil)
+	err := Wrap(errbase.New("error"))
+	checkCallersFrames(t, getCallers(t, err))
+}
+
+func TestFrameCacheStats(t *testing.T) {
+	fc := NewFrameCache(100)
+	setDefaultFrameCache(t, fc)
+	assert.Zero(t, fc.Stats().HitRate())
+	err := Wrap(errbase.New("error"))
+	callers := getCallers(t, err)
+	for range CallersFrames(callers) { //nolint:revive // Consume the iterator.
+	}
+	st := fc.Stats()
+	assert.Equal(t, st.Hits, 0)
+	assert.Equal(t, st.Misses, int64(len(callers)))
+	assert.Equal(t, st.Size, len(callers))
+	for range CallersFrames(callers) { //nolint:revive // Consume the iterator.
+	}
+	st = fc.Stats()
+	assert.Equal(t, st.Hits, int64(len(callers)))
+	assert.Equal(t, st.HitRate(), 0.5)
+	fc.Clear()
+	assert.Zero(t, fc.Stats())
+}
+
+func TestFrameCacheBounded(t *testing.T) {
+	fc := NewFrameCache(0)
+	err := wrapDeep(errbase.New("error"), 100)
+	for _, pc := range getCallers(t, err) {
+		fc.Frames(pc)
+	}
+	assert.LessOrEqual(t, fc.Stats().Size, 64)
+}
+
+func TestCallersFramesAllocs(t *testing.T) {
+	setDefaultFrameCache(t, NewFrameCache(100))
+	err := Wrap(errbase.New("error"))
+	callers := getCallers(t, err)
+	for range CallersFrames(callers) { //nolint:revive // Consume the iterator.
+	}
+	assert.AllocsPerRun(t, 100, func() {
+		for range CallersFrames(callers) { //nolint:revive // Consume the iterator.
+		}
+	}, 0)
+}
+
+func BenchmarkCallersFrames(b *testing.B) {
+	err := Wrap(errbase.New("error"))
+	callers := getCallers(b, err)
+	for b.Loop() {
+		for range CallersFrames(callers) { //nolint:revive // Consume the iterator.
+		}
+	}
+}
+
+func BenchmarkCallersFramesDisabled(b *testing.B) {
+	setDefaultFrameCache(b, nil)
+	err := Wrap(errbase.New("error"))
+	callers := getCallers(b, err)
+	for b.Loop() {
+		for range CallersFrames(callers) { //nolint:revive // Consume the iterator.
+		}
+	}
+}
+
+func BenchmarkVerboseDisabledFrameCache(b *testing.B) {
+	setDefaultFrameCache(b, nil)
+	err := Wrap(errbase.New("error"))
+	v, _ := assert.ErrorAsType[interface {
+		error
+		ErrorVerboseAppend([]byte) []byte
+	}](b, err)
+	var buf []byte
+	for b.Loop() {
+		buf = v.ErrorVerboseAppend(buf)
+		buf = buf[:0]
+	}
+}
@@ -0,0 +1,163 @@
+package errstack
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pierrre/go-libs/syncutil/atomicutil"
+)
+
+// VerboseSource is the [SourceReader] used to show the source code context of the frames in the verbose message.
+//
+// The default value is nil, which disables it.
+var VerboseSource atomicutil.Value[*SourceReader]
+
+// SourceReader reads the source code around the line of stack frames.
+//
+// The files are cached, up to MaxFiles files.
+// The files that can't be read are ignored.
+//
+// It is safe for concurrent use.
+type SourceReader struct {
+	// FS is the file system used to read the files.
+	// If nil, the local file system is used.
+	FS fs.FS
+	// TrimPrefix is removed from the file paths of the frames before they are read from FS (e.g. the directory of the module).
+	// The leading "/" is always removed when FS is set, because [fs.FS] doesn't accept absolute paths.
+	TrimPrefix string
+	// Context is the number of lines shown before and after the line of the frame.
+	Context int
+	// MaxFiles is the maximum number of files in the cache.
+	// The cache is cleared when it is full.
+	// If 0, [DefaultSourceMaxFiles] is used.
+	MaxFiles int
+
+	mu    sync.RWMutex
+	cache map[string][][]byte
+}
+
+// DefaultSourceMaxFiles is the default value of [SourceReader.MaxFiles].
+const DefaultSourceMaxFiles = 100
+
+// SourceLine is a line of source code.
+type SourceLine struct {
+	// Number is the line number, starting at 1.
+	Number int
+	// Text is the content of the line, without the trailing newline.
+	Text string
+	// Current is true for the line of the frame.
+	Current bool
+}
+
+// Lines returns the lines of source code around the line of the frame.
+//
+// It returns nil if the file can't be read, or if the line doesn't exist.
+func (sr *SourceReader) Lines(f runtime.Frame) []SourceLine { //nolint:gocritic // runtime.Frame is large.
+	lines := sr.getFile(f.File)
+	first, last, ok := sr.lineRange(lines, f.Line)
+	if !ok {
+		return nil
+	}
+	sls := make([]SourceLine, 0, last-first+1)
+	for n := first; n <= last; n++ {
+		sls = append(sls, SourceLine{
+			Number:  n,
+			Text:    string(lines[n-1]),
+			Current: n == f.Line,
+		})
+	}
+	return sls
+}
+
+// AppendLines appends the lines of source code around the line of the frame to b.
+//
+// Each line is indented with 2 tabs, the line of the frame is marked with ">".
+// It doesn't append anything if the file can't be read.
+func (sr *SourceReader) AppendLines(b []byte, f runtime.Frame) []byte { //nolint:gocritic // runtime.Frame is large.
+	lines := sr.getFile(f.File)
+	first, last, ok := sr.lineRange(lines, f.Line)
+	if !ok {
+		return b
+	}
+	width := len(strconv.Itoa(last))
+	for n := first; n <= last; n++ {
+		b = append(b, "\t\t"...)
+		if n == f.Line {
+			b = append(b, "> "...)
+		} else {
+			b = append(b, "  "...)
+		}
+		for range width - len(strconv.Itoa(n)) {
+			b = append(b, ' ')
+		}
+		b = strconv.AppendInt(b, int64(n), 10)
+		b = append(b, " | "...)
+		b = append(b, lines[n-1]...)
+		b = append(b, '\n')
+	}
+	return b
+}
+
+func (sr *SourceReader) lineRange(lines [][]byte, line int) (first, last int, ok bool) {
+	if line < 1 || line > len(lines) {
+		return 0, 0, false
+	}
+	ctx := max(sr.Context, 0)
+	first = max(line-ctx, 1)
+	last = min(line+ctx, len(lines))
+	return first, last, true
+}
+
+// getFile returns the lines of the file.
+//
+// The file is read without holding the lock, so concurrent calls don't wait for each other.
+// A file can be read several times concurrently, the last result is cached.
+func (sr *SourceReader) getFile(name string) [][]byte {
+	sr.mu.RLock()
+	lines, ok := sr.cache[name]
+	sr.mu.RUnlock()
+	if ok {
+		return lines
+	}
+	lines = sr.readFile(name)
+	maxFiles := sr.MaxFiles
+	if maxFiles <= 0 {
+		maxFiles = DefaultSourceMaxFiles
+	}
+	sr.mu.Lock()
+	if sr.cache == nil {
+		sr.cache = make(map[string][][]byte)
+	}
+	if len(sr.cache) >= maxFiles {
+		clear(sr.cache)
+	}
+	sr.cache[name] = lines
+	sr.mu.Unlock()
+	return lines
+}
+
+func (sr *SourceReader) readFile(name string) [][]byte {
+	var data []byte
+	var err error
+	if sr.FS != nil {
+		name = strings.TrimPrefix(name, sr.TrimPrefix)
+		name = strings.TrimPrefix(name, "/")
+		data, err = fs.ReadFile(sr.FS, name)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil
+	}
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	lines := bytes.Split(data, []byte("\n"))
+	for i, l := range lines {
+		lines[i] = bytes.TrimSuffix(l, []byte("\r"))
+	}
+	return lines
+}
@@ -0,0 +1,106 @@
+package errstack_test
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pierrre/assert"
+	"github.com/pierrre/errors/errbase"
+	. "github.com/pierrre/errors/errstack"
+	"github.com/pierrre/errors/errverbose"
+)
+
+func TestSourceReaderLines(t *testing.T) {
+	sr := &SourceReader{
+		FS: fstest.MapFS{
+			"src/main.go": &fstest.MapFile{
+				Data: []byte("line 1\nline 2\r\nline 3\nline 4\n"),
+			},
+		},
+		TrimPrefix: "/build",
+		Context:    1,
+	}
+	f := runtime.Frame{
+		File: "/build/src/main.go",
+		Line: 4,
+	}
+	lines := sr.Lines(f)
+	assert.SliceEqual(t, lines, []SourceLine{
+		{Number: 3, Text: "line 3"},
+		{Number: 4, Text: "line 4", Current: true},
+	})
+	f.Line = 2
+	b := sr.AppendLines(nil, f)
+	assert.Equal(t, string(b), "\t\t  1 | line 1\n\t\t> 2 | line 2\n\t\t  3 | line 3\n")
+}
+
+func TestSourceReaderNotFound(t *testing.T) {
+	sr := &SourceReader{
+		FS: fstest.MapFS{},
+	}
+	f := runtime.Frame{
+		File: "/build/src/main.go",
+		Line: 1,
+	}
+	assert.SliceNil(t, sr.Lines(f))
+	assert.SliceLen(t, sr.AppendLines(nil, f), 0)
+}
+
+func TestSourceReaderMaxFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.go": &fstest.MapFile{Data: []byte("a 1\n")},
+		"b.go": &fstest.MapFile{Data: []byte("b 1\n")},
+	}
+	sr := &SourceReader{
+		FS:       fsys,
+		MaxFiles: 1,
+	}
+	fa := runtime.Frame{File: "/a.go", Line: 1}
+	fb := runtime.Frame{File: "/b.go", Line: 1}
+	assert.Equal(t, sr.Lines(fa)[0].Text, "a 1")
+	fsys["a.go"].Data = []byte("a 2\n")
+	assert.Equal(t, sr.Lines(fa)[0].Text, "a 1") // Cached.
+	assert.Equal(t, sr.Lines(fb)[0].Text, "b 1") // Clears the cache.
+	assert.Equal(t, sr.Lines(fa)[0].Text, "a 2")
+}
+
+func TestSourceReaderConcurrent(t *testing.T) {
+	sr := &SourceReader{
+		FS: fstest.MapFS{
+			"a.go": &fstest.MapFile{Data: []byte("a\n")},
+			"b.go": &fstest.MapFile{Data: []byte("b\n")},
+		},
+		MaxFiles: 1,
+	}
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Go(func() {
+			f := runtime.Frame{File: "/a.go", Line: 1}
+			if i%2 == 1 {
+				f.File = "/b.go"
+			}
+			assert.SliceLen(t, sr.Lines(f), 1)
+		})
+	}
+	wg.Wait()
+}
+
+func TestSourceReaderInvalidLine(t *testing.T) {
+	sr := &SourceReader{}
+	_, file, _, _ := runtime.Caller(0)
+	f := runtime.Frame{
+		File: file,
+		Line: 100000,
+	}
+	assert.SliceNil(t, sr.Lines(f))
+}
+
+func TestVerboseSource(t *testing.T) {
+	previous := VerboseSource.Swap(&SourceReader{Context: 1})
+	defer VerboseSource.Store(previous)
+	err := Wrap(errbase.New("error")) // Source line.
+	s := errverbose.String(err)
+	assert.RegexpMatch(t, `\n\t\t> \d+ \| 	err := Wrap\(errbase.New\("error"\)\) // Source line.\n`, s)
+}
@@ -2,11 +2,13 @@
 package errtag
 
 import (
+	"fmt"
 	"iter"
 	"strconv"
 
 	"github.com/pierrre/errors/errappend"
 	"github.com/pierrre/errors/erriter"
+	"github.com/pierrre/errors/errverbose"
 )
 
 // Wrap adds a tag to an error.
@@ -55,6 +57,10 @@ func (err *tag) Unwrap() error {
 	return err.error
 }
 
+func (err *tag) Format(s fmt.State, verb rune) {
+	errverbose.Format(s, verb, err)
+}
+
 func (err *tag) ErrorAppend(b []byte) []byte {
 	return errappend.Append(b, err.error)
 }
@@ -73,8 +79,13 @@ func (err *tag) Tag() (key string, val string) {
 
 // All returns a [iter.Seq2] of tags added to an error.
 func All(err error) iter.Seq2[string, string] {
+	return AllOrder(err, erriter.OrderDepthFirst)
+}
+
+// AllOrder is like [All], but iterates over the error tree in the given [erriter.Order].
+func AllOrder(err error, order erriter.Order) iter.Seq2[string, string] {
 	return func(yield func(string, string) bool) {
-		for err := range erriter.All(err) {
+		for err := range erriter.AllOrder(err, order) {
 			errt, ok := err.(interface {
 				Tag() (key string, val string)
 			})
@@ -90,7 +101,15 @@ func All(err error) iter.Seq2[string, string] {
 }
 
 // Get returns the tags added to an error.
+// For each key, the outermost tag is kept.
 // It may return a nil map if there is no value.
 func Get(err error) map[string]string {
 	return erriter.FirstKeys(All(err))
 }
+
+// GetOrder is like [Get], but the first tag in the given [erriter.Order] is kept for each key.
+//
+// Use [erriter.OrderInnermostFirst] to keep the innermost tag.
+func GetOrder(err error, order erriter.Order) map[string]string {
+	return erriter.FirstKeys(AllOrder(err, order))
+}
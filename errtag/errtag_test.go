@@ -8,6 +8,7 @@ import (
 	"github.com/pierrre/errors"
 	"github.com/pierrre/errors/errappend"
 	"github.com/pierrre/errors/errbase"
+	"github.com/pierrre/errors/erriter"
 	"github.com/pierrre/errors/errmsg"
 	. "github.com/pierrre/errors/errtag"
 	"github.com/pierrre/errors/errverbose"
@@ -78,6 +79,16 @@ func TestOverWrite(t *testing.T) {
 	})
 }
 
+func TestGetOrder(t *testing.T) {
+	err := errbase.New("error")
+	err = Wrap(err, "test", "1")
+	err = Wrap(err, "test", "2")
+	tags := GetOrder(err, erriter.OrderInnermostFirst)
+	assert.MapEqual(t, tags, map[string]string{
+		"test": "1",
+	})
+}
+
 func TestNil(t *testing.T) {
 	err := Wrap(nil, "foo", "bar")
 	assert.NoError(t, err)
@@ -0,0 +1,212 @@
+// Package errtest provides test helpers for errors.
+//
+// The assertion functions report a failure with [testing.TB.Errorf] and return false if the assertion fails.
+//
+// The golden files (see [Golden]) are updated with the [UpdateEnv] environment variable, e.g. "ERRTEST_UPDATE=true go test ./...".
+// This package doesn't define a -update flag, because it would conflict with a flag of the same name defined by the test package.
+// A -update flag defined by the test package is used if it is set.
+package errtest
+
+import (
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pierrre/errors/errignore"
+	"github.com/pierrre/errors/erriter"
+	"github.com/pierrre/errors/errslog"
+	"github.com/pierrre/errors/errstack"
+	"github.com/pierrre/errors/errtag"
+	"github.com/pierrre/errors/errtmp"
+	"github.com/pierrre/errors/errval"
+	"github.com/pierrre/errors/errverbose"
+)
+
+// HasTag asserts that the error has a tag with the given key and value (see [errtag.Get]).
+func HasTag(tb testing.TB, err error, key string, val string) bool {
+	tb.Helper()
+	v, ok := errtag.Get(err)[key]
+	if !ok {
+		tb.Errorf("errtest: tag %q not found in error %q", key, errorString(err))
+		return false
+	}
+	if v != val {
+		tb.Errorf("errtest: tag %q: got %q, want %q", key, v, val)
+		return false
+	}
+	return true
+}
+
+// HasValue asserts that the error has a value with the given key, which is deeply equal to val (see [errval.GetValue]).
+func HasValue(tb testing.TB, err error, key string, val any) bool {
+	tb.Helper()
+	v, ok := errval.GetValue(err, key)
+	if !ok {
+		tb.Errorf("errtest: value %q not found in error %q", key, errorString(err))
+		return false
+	}
+	if !reflect.DeepEqual(v, val) {
+		tb.Errorf("errtest: value %q: got %#v, want %#v", key, v, val)
+		return false
+	}
+	return true
+}
+
+// HasLevel asserts that the error has the given [slog.Level] (see [errslog.GetLevel]).
+func HasLevel(tb testing.TB, err error, level slog.Level) bool {
+	tb.Helper()
+	l, ok := errslog.GetLevel(err)
+	if !ok {
+		tb.Errorf("errtest: level not found in error %q", errorString(err))
+		return false
+	}
+	if l != level {
+		tb.Errorf("errtest: level: got %s, want %s", l, level)
+		return false
+	}
+	return true
+}
+
+// IsTemporary asserts that [errtmp.Is] returns the expected value.
+func IsTemporary(tb testing.TB, err error, expected bool) bool {
+	tb.Helper()
+	if errtmp.Is(err) != expected {
+		tb.Errorf("errtest: temporary: got %t, want %t, for error %q", !expected, expected, errorString(err))
+		return false
+	}
+	return true
+}
+
+// IsIgnored asserts that [errignore.Is] returns the expected value.
+func IsIgnored(tb testing.TB, err error, expected bool) bool {
+	tb.Helper()
+	if errignore.Is(err) != expected {
+		tb.Errorf("errtest: ignored: got %t, want %t, for error %q", !expected, expected, errorString(err))
+		return false
+	}
+	return true
+}
+
+// StackContains asserts that a stack of the error contains a frame of the given function (see [errstack.Frames]).
+//
+// The function is either a full name (e.g. "github.com/user/repo/pkg.Func"), or a suffix after a "/" or a "." (e.g. "pkg.Func" or "Func").
+func StackContains(tb testing.TB, err error, function string) bool {
+	tb.Helper()
+	for fs := range errstack.Frames(err) {
+		for f := range fs {
+			if matchFunction(f.Function, function) {
+				return true
+			}
+		}
+	}
+	tb.Errorf("errtest: function %q not found in the stacks of error %q", function, errorString(err))
+	return false
+}
+
+func matchFunction(name string, function string) bool {
+	return name == function || strings.HasSuffix(name, "/"+function) || strings.HasSuffix(name, "."+function)
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return err.Error()
+}
+
+// Node is a node of an error tree, used by [TreeEqual].
+//
+// A node is a chain of wrapped errors, as shown by [errverbose.Write].
+type Node struct {
+	// Message is the message of the outermost error of the chain.
+	Message string
+	// Children are the nodes of the errors wrapped by a joined error at the end of the chain.
+	Children []Node
+}
+
+// Tree returns the [Node] tree of the error.
+//
+// It returns nil if the error is nil.
+func Tree(err error) *Node {
+	if err == nil {
+		return nil
+	}
+	// The nodes of the current path, indexed by the length of [erriter.Node.Path].
+	// The indexes of the path can't be used, because the nil errors of `Unwrap() []error` are skipped by [erriter.Walk].
+	var nodes []*Node
+	erriter.Walk(err, func(n erriter.Node) erriter.WalkAction {
+		if n.Depth != 0 {
+			return erriter.WalkContinue
+		}
+		msg := "<cycle detected>"
+		switch {
+		case n.Truncated:
+			msg = "<truncated>"
+		case !n.Cycle:
+			msg = n.Err.Error()
+		}
+		if len(nodes) == 0 {
+			nodes = append(nodes, &Node{Message: msg})
+			return erriter.WalkContinue
+		}
+		parent := nodes[len(n.Path)-1]
+		parent.Children = append(parent.Children, Node{Message: msg})
+		nodes = append(nodes[:len(n.Path)], &parent.Children[len(parent.Children)-1])
+		return erriter.WalkContinue
+	})
+	return nodes[0]
+}
+
+// TreeEqual asserts that the error has the expected tree shape (see [Tree]).
+func TreeEqual(tb testing.TB, err error, expected Node) bool {
+	tb.Helper()
+	tree := Tree(err)
+	if tree == nil || !reflect.DeepEqual(*tree, expected) {
+		var got string
+		if tree != nil {
+			got = tree.String()
+		}
+		tb.Errorf("errtest: tree not equal:\ngot:\n%swant:\n%s", got, expected.String())
+		return false
+	}
+	return true
+}
+
+// String returns a representation of the tree, with a node per line, indented by depth.
+func (n Node) String() string {
+	var sb strings.Builder
+	n.write(&sb, 0)
+	return sb.String()
+}
+
+func (n Node) write(sb *strings.Builder, depth int) {
+	sb.WriteString(strings.Repeat("\t", depth))
+	sb.WriteString(strconv.Quote(n.Message))
+	sb.WriteByte('\n')
+	for _, c := range n.Children {
+		c.write(sb, depth+1)
+	}
+}
+
+var (
+	locationRegexp = regexp.MustCompile(`(?m)^(\t+)(?:.*/)?([^/\s]+):\d+$`)
+	pointerRegexp  = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+)
+
+// NormalizedVerbose returns the verbose message of the error (see [errverbose.String]), normalized with [Normalize].
+func NormalizedVerbose(err error) string {
+	return Normalize(errverbose.String(err))
+}
+
+// Normalize replaces the varying parts of a verbose message with placeholders, so it can be compared to a golden value:
+//   - the directories of the stack frame files are replaced with "<path>"
+//   - the line numbers of the stack frames are replaced with "<line>"
+//   - the pointer values are replaced with "<pointer>"
+func Normalize(s string) string {
+	s = locationRegexp.ReplaceAllString(s, "$1<path>/$2:<line>")
+	s = pointerRegexp.ReplaceAllString(s, "<pointer>")
+	return s
+}
@@ -0,0 +1,182 @@
+package errtest_test
+
+import (
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/pierrre/assert"
+	"github.com/pierrre/errors"
+	"github.com/pierrre/errors/errbase"
+	"github.com/pierrre/errors/errignore"
+	"github.com/pierrre/errors/errslog"
+	"github.com/pierrre/errors/errtag"
+	. "github.com/pierrre/errors/errtest"
+	"github.com/pierrre/errors/errtmp"
+	"github.com/pierrre/errors/errval"
+)
+
+type testTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *testTB) Helper() {}
+
+func (tb *testTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func newTestTB(t *testing.T) *testTB {
+	t.Helper()
+	return &testTB{TB: t}
+}
+
+func newTestError() error {
+	err := errors.Join(
+		errors.New("error a"),
+		errors.Wrap(errors.Join(errbase.New("error b"), errbase.New("error c")), "wrap"),
+	)
+	err = errtag.Wrap(err, "foo", "bar")
+	err = errval.Wrap(err, "count", 3)
+	err = errslog.WrapLevel(err, slog.LevelWarn)
+	err = errtmp.Wrap(err, true)
+	return err
+}
+
+func TestHasTag(t *testing.T) {
+	err := newTestError()
+	assert.True(t, HasTag(t, err, "foo", "bar"))
+	tb := newTestTB(t)
+	assert.False(t, HasTag(tb, err, "foo", "baz"))
+	assert.False(t, HasTag(tb, err, "unknown", "bar"))
+	assert.SliceEqual(t, tb.errors, []string{
+		`errtest: tag "foo": got "bar", want "baz"`,
+		`errtest: tag "unknown" not found in error "error a\nwrap: error b\nerror c"`,
+	})
+}
+
+func TestHasValue(t *testing.T) {
+	err := newTestError()
+	assert.True(t, HasValue(t, err, "count", 3))
+	tb := newTestTB(t)
+	assert.False(t, HasValue(tb, err, "count", 4))
+	assert.False(t, HasValue(tb, err, "unknown", 3))
+	assert.SliceEqual(t, tb.errors, []string{
+		`errtest: value "count": got 3, want 4`,
+		`errtest: value "unknown" not found in error "error a\nwrap: error b\nerror c"`,
+	})
+}
+
+func TestHasLevel(t *testing.T) {
+	err := newTestError()
+	assert.True(t, HasLevel(t, err, slog.LevelWarn))
+	tb := newTestTB(t)
+	assert.False(t, HasLevel(tb, err, slog.LevelError))
+	assert.False(t, HasLevel(tb, errbase.New("error"), slog.LevelError))
+	assert.SliceEqual(t, tb.errors, []string{
+		`errtest: level: got WARN, want ERROR`,
+		`errtest: level not found in error "error"`,
+	})
+}
+
+func TestIsTemporary(t *testing.T) {
+	err := newTestError()
+	assert.True(t, IsTemporary(t, err, true))
+	tb := newTestTB(t)
+	assert.False(t, IsTemporary(tb, err, false))
+	assert.SliceLen(t, tb.errors, 1)
+}
+
+func TestIsIgnored(t *testing.T) {
+	err := errignore.Wrap(errbase.New("error"))
+	assert.True(t, IsIgnored(t, err, true))
+	tb := newTestTB(t)
+	assert.False(t, IsIgnored(tb, err, false))
+	assert.SliceEqual(t, tb.errors, []string{
+		`errtest: ignored: got true, want false, for error "error"`,
+	})
+}
+
+func TestStackContains(t *testing.T) {
+	err := newTestError()
+	assert.True(t, StackContains(t, err, "github.com/pierrre/errors/errtest_test.newTestError"))
+	assert.True(t, StackContains(t, err, "errtest_test.newTestError"))
+	assert.True(t, StackContains(t, err, "newTestError"))
+	tb := newTestTB(t)
+	assert.False(t, StackContains(tb, err, "TestNewTestError"))
+	assert.SliceLen(t, tb.errors, 1)
+}
+
+func TestTreeEqual(t *testing.T) {
+	err := newTestError()
+	expected := Node{
+		Message: "error a\nwrap: error b\nerror c",
+		Children: []Node{
+			{Message: "error a"},
+			{
+				Message: "wrap: error b\nerror c",
+				Children: []Node{
+					{Message: "error b"},
+					{Message: "error c"},
+				},
+			},
+		},
+	}
+	assert.True(t, TreeEqual(t, err, expected))
+	tb := newTestTB(t)
+	assert.False(t, TreeEqual(tb, errbase.New("error"), expected))
+	assert.False(t, TreeEqual(tb, nil, expected))
+	assert.SliceLen(t, tb.errors, 2)
+	assert.Equal(t, tb.errors[0], "errtest: tree not equal:\ngot:\n\"error\"\nwant:\n\"error a\\nwrap: error b\\nerror c\"\n\t\"error a\"\n\t\"wrap: error b\\nerror c\"\n\t\t\"error b\"\n\t\t\"error c\"\n")
+}
+
+type testNilJoinError struct {
+	errs []error
+}
+
+func (e *testNilJoinError) Error() string {
+	return "join"
+}
+
+func (e *testNilJoinError) Unwrap() []error {
+	return e.errs
+}
+
+func TestTreeNilJoined(t *testing.T) {
+	err := &testNilJoinError{errs: []error{
+		nil,
+		errbase.New("error a"),
+		nil,
+		&testNilJoinError{errs: []error{nil, errbase.New("error b")}},
+	}}
+	assert.True(t, TreeEqual(t, err, Node{
+		Message: "join",
+		Children: []Node{
+			{Message: "error a"},
+			{
+				Message: "join",
+				Children: []Node{
+					{Message: "error b"},
+				},
+			},
+		},
+	}))
+}
+
+func TestTreeNil(t *testing.T) {
+	assert.Zero(t, Tree(nil))
+}
+
+func TestNormalizedVerbose(t *testing.T) {
+	err := errors.New("error")
+	err = errval.Wrap(err, "pointer", fmt.Sprintf("%p", err))
+	s := NormalizedVerbose(err)
+	assert.RegexpMatch(t, `^error\nvalue pointer = \[string\] \(len=\d+\) "<pointer>"\nstack:\ngithub.com/pierrre/errors/errtest_test.TestNormalizedVerbose\n\t<path>/errtest_test.go:<line>\n`, s)
+	assert.StringNotContains(t, s, "/root/")
+}
+
+func TestNormalize(t *testing.T) {
+	s := Normalize("stack:\nmain.main\n\t/home/user/src/main.go:12\nvalue = 0xc000123456\n")
+	assert.Equal(t, s, "stack:\nmain.main\n\t<path>/main.go:<line>\nvalue = <pointer>\n")
+}
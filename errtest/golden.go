@@ -0,0 +1,196 @@
+package errtest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pierrre/errors/erriter"
+	"github.com/pierrre/errors/errstack"
+	"github.com/pierrre/errors/errverbose"
+)
+
+// UpdateEnv is the name of the environment variable that enables the update of the golden files.
+//
+// Its value is parsed with [strconv.ParseBool].
+const UpdateEnv = "ERRTEST_UPDATE"
+
+// updateFlagName is the name of the flag that enables the update of the golden files.
+//
+// It is not defined by this package, but it is used if the test binary defines it.
+const updateFlagName = "update"
+
+func isUpdate() bool {
+	update, _ := strconv.ParseBool(os.Getenv(UpdateEnv))
+	if update {
+		return true
+	}
+	f := flag.Lookup(updateFlagName)
+	return f != nil && f.Value.String() == "true"
+}
+
+// Describe returns a structured description of the error, which is stable between runs.
+//
+// It contains a section per chain of wrapped errors (see [errverbose.Write]), with:
+//   - the message
+//   - the verbose messages (tags, values, etc.), normalized with [Normalize]
+//   - the function names of the stack frames, without the file paths and line numbers
+//
+// The sections of the errors wrapped by a joined error are indented under it.
+func Describe(err error) string {
+	if err == nil {
+		return "<nil>\n"
+	}
+	var sb strings.Builder
+	indent := ""
+	erriter.Walk(err, func(n erriter.Node) erriter.WalkAction {
+		if n.Depth == 0 {
+			indent = strings.Repeat("\t", len(n.Path))
+			if len(n.Path) > 0 {
+				sb.WriteString(strings.Repeat("\t", len(n.Path)-1))
+				sb.WriteString("sub error ")
+				sb.WriteString(strconv.Itoa(n.Path[len(n.Path)-1]))
+				sb.WriteString(":\n")
+			}
+		}
+		switch {
+		case n.Cycle:
+			writeDescribeLine(&sb, indent, "<cycle detected>")
+			return erriter.WalkSkip
+		case n.Truncated:
+			writeDescribeLine(&sb, indent, "<truncated>")
+			return erriter.WalkSkip
+		}
+		if n.Depth == 0 {
+			writeDescribeLine(&sb, indent, "message: "+strconv.Quote(n.Err.Error()))
+		}
+		describeVerbose(&sb, indent, n.Err)
+		return erriter.WalkContinue
+	})
+	return sb.String()
+}
+
+func describeVerbose(sb *strings.Builder, indent string, err error) {
+	if errs, ok := err.(interface{ StackFrames() []uintptr }); ok {
+		writeDescribeLine(sb, indent, "stack:")
+		for f := range errstack.CallersFrames(errs.StackFrames()) {
+			writeDescribeLine(sb, indent, "\t"+f.Function)
+		}
+		return
+	}
+	b, ok := errverbose.AppendVerbose(nil, err)
+	if !ok {
+		return
+	}
+	for line := range strings.Lines(Normalize(string(b))) {
+		writeDescribeLine(sb, indent, strings.TrimSuffix(line, "\n"))
+	}
+}
+
+func writeDescribeLine(sb *strings.Builder, indent string, s string) {
+	sb.WriteString(indent)
+	sb.WriteString(s)
+	sb.WriteByte('\n')
+}
+
+// Golden asserts that the description of the error (see [Describe]) is equal to the content of the golden file "testdata/<test name>.golden".
+//
+// If the [UpdateEnv] environment variable is true, or if the test binary defines a -update flag that is set, the golden file is written instead.
+// On mismatch, the failure message contains a line diff.
+func Golden(tb testing.TB, err error) bool {
+	tb.Helper()
+	return GoldenString(tb, Describe(err))
+}
+
+// GoldenString is like [Golden], but compares a string.
+func GoldenString(tb testing.TB, s string) bool {
+	tb.Helper()
+	fp := goldenFilePath(tb.Name())
+	if isUpdate() {
+		err := os.MkdirAll(filepath.Dir(fp), 0o755) //nolint:gosec // The directory is not sensitive.
+		if err == nil {
+			err = os.WriteFile(fp, []byte(s), 0o644) //nolint:gosec // The file is not sensitive.
+		}
+		if err != nil {
+			tb.Errorf("errtest: update golden file %q: %v", fp, err)
+			return false
+		}
+		return true
+	}
+	b, err := os.ReadFile(fp)
+	if err != nil {
+		tb.Errorf("errtest: read golden file %q (set %s=true to create it): %v", fp, UpdateEnv, err)
+		return false
+	}
+	expected := string(b)
+	if s != expected {
+		tb.Errorf("errtest: golden file %q mismatch (-want +got):\n%s", fp, DiffLines(expected, s))
+		return false
+	}
+	return true
+}
+
+func goldenFilePath(name string) string {
+	name = strings.NewReplacer("/", "__", "\\", "_", ":", "_", " ", "_").Replace(name)
+	return filepath.Join("testdata", name+".golden")
+}
+
+// DiffLines returns a line diff between a and b.
+//
+// The lines only in a are prefixed with "-", the lines only in b with "+", and the common lines with " ".
+// It returns an empty string if a and b are equal.
+func DiffLines(a, b string) string {
+	if a == b {
+		return ""
+	}
+	al := splitLines(a)
+	bl := splitLines(b)
+	// Longest common subsequence, computed from the end.
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(bl) - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(al) || j < len(bl) {
+		switch {
+		case i < len(al) && j < len(bl) && al[i] == bl[j]:
+			writeDiffLine(&sb, ' ', al[i])
+			i++
+			j++
+		case i < len(al) && (j == len(bl) || lcs[i+1][j] >= lcs[i][j+1]):
+			writeDiffLine(&sb, '-', al[i])
+			i++
+		default:
+			writeDiffLine(&sb, '+', bl[j])
+			j++
+		}
+	}
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	var lines []string
+	for line := range strings.Lines(s) {
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+	return lines
+}
+
+func writeDiffLine(sb *strings.Builder, prefix byte, line string) {
+	sb.WriteByte(prefix)
+	sb.WriteString(line)
+	sb.WriteByte('\n')
+}
@@ -0,0 +1,84 @@
+package errtest_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pierrre/assert"
+	"github.com/pierrre/errors/errbase"
+	. "github.com/pierrre/errors/errtest"
+)
+
+type testNameTB struct {
+	*testTB
+	name string
+}
+
+func (tb *testNameTB) Name() string {
+	return tb.name
+}
+
+func TestGolden(t *testing.T) {
+	err := newTestError()
+	Golden(t, err)
+}
+
+func TestGoldenSubTest(t *testing.T) {
+	t.Run("Sub", func(t *testing.T) {
+		Golden(t, errbase.New("error"))
+	})
+}
+
+// setTestGoldenDir runs the test in a temporary directory, so the golden files of the package are not read or written.
+func setTestGoldenDir(tb testing.TB) {
+	tb.Helper()
+	tb.Chdir(tb.TempDir())
+	tb.Setenv(UpdateEnv, "false")
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	setTestGoldenDir(t)
+	err := os.Mkdir("testdata", 0o755) //nolint:gosec // The directory is not sensitive.
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join("testdata", "TestGoldenMismatch__Sub.golden"), []byte("message: \"error\"\n"), 0o644) //nolint:gosec // The file is not sensitive.
+	assert.NoError(t, err)
+	tb := &testNameTB{
+		testTB: newTestTB(t),
+		name:   "TestGoldenMismatch/Sub",
+	}
+	assert.False(t, GoldenString(tb, "message: \"other\"\n"))
+	assert.SliceEqual(t, tb.errors, []string{
+		"errtest: golden file \"testdata/TestGoldenMismatch__Sub.golden\" mismatch (-want +got):\n-message: \"error\"\n+message: \"other\"\n",
+	})
+}
+
+func TestGoldenNotFound(t *testing.T) {
+	setTestGoldenDir(t)
+	tb := &testNameTB{
+		testTB: newTestTB(t),
+		name:   "TestGoldenNotFound",
+	}
+	assert.False(t, Golden(tb, errbase.New("error")))
+	assert.SliceLen(t, tb.errors, 1)
+	_, err := os.Stat(filepath.Join("testdata", "TestGoldenNotFound.golden"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv(UpdateEnv, "true")
+	assert.True(t, GoldenString(t, "test\n"))
+	b, err := os.ReadFile(filepath.Join("testdata", "TestGoldenUpdate.golden"))
+	assert.NoError(t, err)
+	assert.Equal(t, string(b), "test\n")
+}
+
+func TestDescribeNil(t *testing.T) {
+	assert.Equal(t, Describe(nil), "<nil>\n")
+}
+
+func TestDiffLines(t *testing.T) {
+	assert.Equal(t, DiffLines("a\nb\nc\n", "a\nb\nc\n"), "")
+	assert.Equal(t, DiffLines("a\nb\nc\n", "a\nx\nc\nd\n"), " a\n-b\n+x\n c\n+d\n")
+}
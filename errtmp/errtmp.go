@@ -2,10 +2,13 @@
 package errtmp
 
 import (
+	"context"
+	"fmt"
 	"strconv"
 
-	"github.com/pierrre/errors"
 	"github.com/pierrre/errors/errappend"
+	"github.com/pierrre/errors/erriter"
+	"github.com/pierrre/errors/errverbose"
 )
 
 // Wrap marks an error as temporary.
@@ -30,6 +33,10 @@ func (err *temporary) Unwrap() error {
 	return err.error
 }
 
+func (err *temporary) Format(s fmt.State, verb rune) {
+	errverbose.Format(s, verb, err)
+}
+
 func (err *temporary) ErrorAppend(b []byte) []byte {
 	return errappend.Append(b, err.error)
 }
@@ -49,13 +56,12 @@ func (err *temporary) Temporary() bool {
 // By default, an error is considered temporary.
 // This is the opposite of the usual convention where an error that does not implement a Temporary() bool method is considered not temporary.
 // To explicitly mark an error as not temporary, wrap it with [Wrap] and the value false.
+//
+// An error caused by a [context.Context] cancellation ([context.Canceled]) is not considered temporary by default, because retrying it is pointless.
 func Is(err error) bool {
-	var werr interface {
-		Temporary() bool
-	}
-	ok := errors.As(err, &werr)
+	werr, ok := erriter.Find[interface{ Temporary() bool }](err)
 	if ok {
 		return werr.Temporary()
 	}
-	return true
+	return !erriter.Is(err, context.Canceled)
 }
@@ -1,6 +1,7 @@
 package errtmp_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -43,6 +44,24 @@ func TestDefault(t *testing.T) {
 	assert.True(t, temporary)
 }
 
+func TestCanceled(t *testing.T) {
+	err := errors.Wrap(context.Canceled, "test")
+	temporary := Is(err)
+	assert.False(t, temporary)
+}
+
+func TestCanceledWrapTrue(t *testing.T) {
+	err := Wrap(context.Canceled, true)
+	temporary := Is(err)
+	assert.True(t, temporary)
+}
+
+func TestDeadlineExceeded(t *testing.T) {
+	err := errors.Wrap(context.DeadlineExceeded, "test")
+	temporary := Is(err)
+	assert.True(t, temporary)
+}
+
 func TestNil(t *testing.T) {
 	err := Wrap(nil, true)
 	assert.NoError(t, err)
@@ -91,7 +110,7 @@ func TestIsAllocs(t *testing.T) {
 	var res bool
 	assert.AllocsPerRun(t, 100, func() {
 		res = Is(err)
-	}, 1)
+	}, 0)
 	testSink = res
 }
 
@@ -2,11 +2,14 @@
 package errval
 
 import (
+	"fmt"
 	"io"
 	"iter"
+	"unicode/utf8"
 
 	"github.com/pierrre/errors/errappend"
 	"github.com/pierrre/errors/erriter"
+	"github.com/pierrre/errors/errverbose"
 	"github.com/pierrre/go-libs/bytesutil"
 	"github.com/pierrre/go-libs/syncutil/atomicutil"
 	"github.com/pierrre/pretty"
@@ -52,18 +55,38 @@ func (err *value) Unwrap() error {
 	return err.error
 }
 
+func (err *value) Format(s fmt.State, verb rune) {
+	errverbose.Format(s, verb, err)
+}
+
 func (err *value) ErrorAppend(b []byte) []byte {
 	return errappend.Append(b, err.error)
 }
 
 func (err *value) ErrorVerboseAppend(b []byte) []byte {
+	return err.ErrorVerboseAppendLimits(b, errverbose.DefaultLimits.Load())
+}
+
+func (err *value) ErrorVerboseAppendLimits(b []byte, limits errverbose.Limits) []byte {
 	b = append(b, "value "...)
 	b = append(b, err.key...)
 	b = append(b, " = "...)
 	bw := bytesWriterPool.Get()
 	defer bytesWriterPool.Put(bw)
 	VerboseWriter.Load()(bw, err.val)
-	b = append(b, *bw...)
+	v := *bw
+	if limits.MaxValueLength > 0 && len(v) > limits.MaxValueLength {
+		end := limits.MaxValueLength
+		for end > 0 && !utf8.RuneStart(v[end]) {
+			end--
+		}
+		b = append(b, v[:end]...)
+		b = append(b, "... (truncated, "...)
+		b = errverbose.AppendCount(b, len(v))
+		b = append(b, " bytes)"...)
+		return b
+	}
+	b = append(b, v...)
 	return b
 }
 
@@ -73,8 +96,13 @@ func (err *value) Value() (key string, val any) {
 
 // All returns a [iter.Seq2] of values added to an error.
 func All(err error) iter.Seq2[string, any] {
+	return AllOrder(err, erriter.OrderDepthFirst)
+}
+
+// AllOrder is like [All], but iterates over the error tree in the given [erriter.Order].
+func AllOrder(err error, order erriter.Order) iter.Seq2[string, any] {
 	return func(yield func(string, any) bool) {
-		for err := range erriter.All(err) {
+		for err := range erriter.AllOrder(err, order) {
 			errv, ok := err.(interface {
 				Value() (key string, val any)
 			})
@@ -90,16 +118,29 @@ func All(err error) iter.Seq2[string, any] {
 }
 
 // Get returns the values added to an error.
-// // It may return a nil map if there is no value.
+// For each key, the outermost value is kept.
+// It may return a nil map if there is no value.
 func Get(err error) map[string]any {
 	return erriter.FirstKeys(All(err))
 }
 
+// GetOrder is like [Get], but the first value in the given [erriter.Order] is kept for each key.
+//
+// Use [erriter.OrderInnermostFirst] to keep the innermost value.
+func GetOrder(err error, order erriter.Order) map[string]any {
+	return erriter.FirstKeys(AllOrder(err, order))
+}
+
 // GetValue returns the first value added to an error for the given key.
 //
 // It returns false if the key is not found.
 func GetValue(err error, key string) (any, bool) {
-	for k, v := range All(err) {
+	return GetValueOrder(err, key, erriter.OrderDepthFirst)
+}
+
+// GetValueOrder is like [GetValue], but returns the first value in the given [erriter.Order].
+func GetValueOrder(err error, key string, order erriter.Order) (any, bool) {
+	for k, v := range AllOrder(err, order) {
 		if k == key {
 			return v, true
 		}
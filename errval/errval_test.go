@@ -8,6 +8,7 @@ import (
 	"github.com/pierrre/errors"
 	"github.com/pierrre/errors/errappend"
 	"github.com/pierrre/errors/errbase"
+	"github.com/pierrre/errors/erriter"
 	"github.com/pierrre/errors/errmsg"
 	. "github.com/pierrre/errors/errval"
 	"github.com/pierrre/errors/errverbose"
@@ -42,6 +43,16 @@ func TestOverWrite(t *testing.T) {
 	})
 }
 
+func TestGetOrder(t *testing.T) {
+	err := errbase.New("error")
+	err = Wrap(err, "test", 1)
+	err = Wrap(err, "test", 2)
+	vals := GetOrder(err, erriter.OrderInnermostFirst)
+	assert.MapEqual(t, vals, map[string]any{
+		"test": 1,
+	})
+}
+
 func TestNil(t *testing.T) {
 	err := Wrap(nil, "foo", "bar")
 	assert.NoError(t, err)
@@ -176,6 +187,15 @@ func TestGetValueOverWrite(t *testing.T) {
 	assert.Equal(t, val, 2)
 }
 
+func TestGetValueOrder(t *testing.T) {
+	err := errbase.New("error")
+	err = Wrap(err, "test", 1)
+	err = Wrap(err, "test", 2)
+	v, ok := GetValueOrder(err, "test", erriter.OrderInnermostFirst)
+	assert.True(t, ok)
+	assert.Equal(t, v, any(1))
+}
+
 func TestGetValueEmpty(t *testing.T) {
 	err := errbase.New("error")
 	val, ok := GetValue(err, "foo")
@@ -263,3 +283,11 @@ func BenchmarkVerbose(b *testing.B) {
 	}
 	testSink = buf
 }
+
+func TestVerboseMaxValueLength(t *testing.T) {
+	err := errbase.New("error")
+	err = Wrap(err, "foo", "abcdefghijklmnopqrstuvwxyz")
+	v, _ := assert.ErrorAsType[errverbose.LimitsAppendInterface](t, err)
+	b := v.ErrorVerboseAppendLimits(nil, errverbose.Limits{MaxValueLength: 20})
+	assert.Equal(t, string(b), `value foo = [string] (len=26) "a... (truncated, 46 bytes)`)
+}
@@ -6,10 +6,8 @@ import (
 	"io"
 	"strconv"
 
-	"github.com/pierrre/errors/errappend"
 	"github.com/pierrre/errors/erriter"
 	"github.com/pierrre/go-libs/bytesutil"
-	"github.com/pierrre/go-libs/syncutil"
 )
 
 // Interface is an error that provides verbose information.
@@ -34,21 +32,21 @@ type AppendInterface interface {
 	ErrorVerboseAppend(b []byte) []byte
 }
 
-var depthPool = syncutil.Pool[*[]int]{
-	New: func() *[]int {
-		v := make([]int, 100)
-		return &v
-	},
-}
-
 // Write writes the error's verbose message to the writer.
 //
 // The first line is the error's message.
 // The following lines are the verbose message of the error chain.
+//
+// The error tree is traversed with [erriter.Walk].
+// The errors that are part of a cycle are replaced with "<cycle detected>", and the errors that exceed the limits are replaced with "<truncated>".
+//
+// The output is limited by [DefaultLimits].
 func Write(w io.Writer, err error) {
-	depthP := depthPool.Get()
-	defer depthPool.Put(depthP)
-	depth := (*depthP)[:0]
+	WriteLimits(w, err, DefaultLimits.Load())
+}
+
+// WriteLimits is like [Write], but uses the given [Limits].
+func WriteLimits(w io.Writer, err error, limits Limits) {
 	bw, ok := w.(*bytesutil.Writer)
 	if !ok {
 		bw = bytesWriterPool.Get()
@@ -57,35 +55,189 @@ func Write(w io.Writer, err error) {
 			bytesWriterPool.Put(bw)
 		}()
 	}
-	write(bw, err, depth)
+	write(bw, err, limits)
+}
+
+// Style styles the parts of a verbose message written by [WriteStyle], e.g. with ANSI colors.
+//
+// A nil function writes the part unchanged.
+// A style must not span multiple lines, because the verbose message is truncated at a line boundary if it exceeds [Limits.MaxBytes].
+type Style struct {
+	// Message styles the message of an error.
+	Message func(b []byte, msg []byte) []byte
+	// Verbose styles the verbose message of an error (see [AppendVerbose]).
+	Verbose func(b []byte, err error, verbose []byte) []byte
+	// Note styles the notes written by this package: "<cycle detected>", "<truncated>", "... and N more errors" and "... (truncated, N bytes)".
+	Note func(b []byte, note []byte) []byte
 }
 
-func write(bw *bytesutil.Writer, err error, depth []int) {
-	writeSub(bw, depth)
+// WriteStyle is like [WriteLimits], but styles the parts of the verbose message with the given [Style].
+func WriteStyle(w io.Writer, err error, limits Limits, style *Style) {
+	bw, ok := w.(*bytesutil.Writer)
+	if !ok {
+		bw = bytesWriterPool.Get()
+		defer func() {
+			_, _ = w.Write(*bw)
+			bytesWriterPool.Put(bw)
+		}()
+	}
+	writeStyle(bw, err, limits, style)
+}
+
+func write(bw *bytesutil.Writer, err error, limits Limits) {
+	writeStyle(bw, err, limits, nil)
+}
+
+func writeStyle(bw *bytesutil.Writer, err error, limits Limits, style *Style) {
 	if err == nil {
 		bw.AppendString("<nil>\n")
 		return
 	}
-	*bw = errappend.Append(*bw, err)
-	bw.AppendByte('\n')
-	for ; err != nil; err = writeNext(bw, err, depth) {
-		switch v := err.(type) { //nolint:errorlint // We want to check for specific error types.
-		case Interface:
-			bw.AppendString(v.ErrorVerbose())
-			bw.AppendByte('\n')
-		case AppendInterface:
-			*bw = v.ErrorVerboseAppend(*bw)
-			bw.AppendByte('\n')
+	vw := &verboseWriter{
+		bw:     bw,
+		limits: limits,
+		style:  style,
+		start:  len(*bw),
+	}
+	erriter.Walk(err, vw.node)
+	vw.truncate()
+}
+
+// verboseWriter writes the nodes of the error tree.
+type verboseWriter struct {
+	bw     *bytesutil.Writer
+	limits Limits
+	style  *Style
+	start  int
+	tmp    []byte
+}
+
+func (vw *verboseWriter) node(n erriter.Node) erriter.WalkAction {
+	if vw.limits.MaxBytes > 0 && len(*vw.bw)-vw.start > vw.limits.MaxBytes {
+		return erriter.WalkStop
+	}
+	if n.Depth == 0 && len(n.Path) > 0 && vw.limits.MaxJoinErrors > 0 {
+		i := n.Path[len(n.Path)-1]
+		if i >= vw.limits.MaxJoinErrors {
+			if i == vw.limits.MaxJoinErrors {
+				errs, _ := erriter.Unwrap(n.Parent)
+				vw.bw.AppendByte('\n')
+				vw.note(AppendMoreErrors(vw.tmp[:0], len(errs)-i))
+				vw.bw.AppendByte('\n')
+			}
+			return erriter.WalkSkip
 		}
 	}
+	if n.Depth == 0 {
+		writeSub(vw.bw, n.Path)
+	}
+	switch {
+	case n.Cycle:
+		vw.noteLine("<cycle detected>")
+		return erriter.WalkSkip
+	case n.Truncated:
+		vw.noteLine("<truncated>")
+		return erriter.WalkSkip
+	}
+	if n.Depth == 0 {
+		vw.message(n.Err)
+		vw.bw.AppendByte('\n')
+	}
+	vw.verbose(n.Err)
+	return erriter.WalkContinue
+}
+
+func (vw *verboseWriter) message(err error) {
+	if vw.style == nil || vw.style.Message == nil {
+		*vw.bw = AppendMessageLimits(*vw.bw, err, vw.limits)
+		return
+	}
+	vw.tmp = AppendMessageLimits(vw.tmp[:0], err, vw.limits)
+	*vw.bw = vw.style.Message(*vw.bw, vw.tmp)
 }
 
-func writeSub(bw *bytesutil.Writer, depth []int) {
-	if len(depth) == 0 {
+func (vw *verboseWriter) verbose(err error) {
+	if vw.style == nil || vw.style.Verbose == nil {
+		writeVerbose(vw.bw, err, vw.limits)
+		return
+	}
+	var ok bool
+	vw.tmp, ok = appendVerbose(vw.tmp[:0], err, vw.limits)
+	if ok {
+		*vw.bw = vw.style.Verbose(*vw.bw, err, vw.tmp)
+		vw.bw.AppendByte('\n')
+	}
+}
+
+func (vw *verboseWriter) noteLine(note string) {
+	vw.tmp = append(vw.tmp[:0], note...)
+	vw.note(vw.tmp)
+	vw.bw.AppendByte('\n')
+}
+
+func (vw *verboseWriter) note(note []byte) {
+	vw.tmp = note
+	if vw.style == nil || vw.style.Note == nil {
+		vw.bw.Append(note)
+		return
+	}
+	*vw.bw = vw.style.Note(*vw.bw, note)
+}
+
+// truncate truncates the verbose message if it exceeds [Limits.MaxBytes].
+//
+// If there is a style, it is truncated at a line boundary, so a style is not cut.
+func (vw *verboseWriter) truncate() {
+	total, ok := truncateBytes(vw.bw, vw.start, vw.limits.MaxBytes, vw.style != nil)
+	if !ok {
+		return
+	}
+	vw.bw.AppendByte('\n')
+	vw.note(appendTruncated(vw.tmp[:0], total))
+	vw.bw.AppendByte('\n')
+}
+
+func writeVerbose(bw *bytesutil.Writer, err error, limits Limits) {
+	var ok bool
+	*bw, ok = appendVerbose(*bw, err, limits)
+	if ok {
+		bw.AppendByte('\n')
+	}
+}
+
+// AppendVerbose appends the verbose message provided by the error itself to b, without the verbose messages of the wrapped errors.
+//
+// The error must implement [Interface], [AppendInterface] or [LimitsAppendInterface], otherwise it returns false and b is not modified.
+// It doesn't append a trailing newline.
+//
+// It allows other renderers to show the same verbose contributions as [Write].
+func AppendVerbose(b []byte, err error) ([]byte, bool) {
+	return appendVerbose(b, err, DefaultLimits.Load())
+}
+
+// AppendVerboseLimits is like [AppendVerbose], but uses the given [Limits].
+func AppendVerboseLimits(b []byte, err error, limits Limits) ([]byte, bool) {
+	return appendVerbose(b, err, limits)
+}
+
+func appendVerbose(b []byte, err error, limits Limits) ([]byte, bool) {
+	switch v := err.(type) { //nolint:errorlint // We want to check for specific error types.
+	case LimitsAppendInterface:
+		return v.ErrorVerboseAppendLimits(b, limits), true
+	case Interface:
+		return append(b, v.ErrorVerbose()...), true
+	case AppendInterface:
+		return v.ErrorVerboseAppend(b), true
+	}
+	return b, false
+}
+
+func writeSub(bw *bytesutil.Writer, path []int) {
+	if len(path) == 0 {
 		return
 	}
 	bw.AppendString("\nSub error ")
-	for i, d := range depth {
+	for i, d := range path {
 		if i > 0 {
 			bw.AppendString(".")
 		}
@@ -94,14 +246,6 @@ func writeSub(bw *bytesutil.Writer, depth []int) {
 	bw.AppendString(": ")
 }
 
-func writeNext(bw *bytesutil.Writer, err error, depth []int) error {
-	errs, err := erriter.Unwrap(err)
-	for i, e := range errs {
-		write(bw, e, append(depth, i))
-	}
-	return err
-}
-
 var bytesWriterPool = &bytesutil.WriterPool{}
 
 // String returns the error's verbose message as a string.
@@ -9,6 +9,8 @@ import (
 
 	"github.com/pierrre/assert"
 	"github.com/pierrre/errors/errbase"
+	"github.com/pierrre/errors/erriter"
+	"github.com/pierrre/errors/errtag"
 	. "github.com/pierrre/errors/errverbose"
 )
 
@@ -162,6 +164,35 @@ verbose
 	assert.Equal(t, s, expected)
 }
 
+type testCycleError struct{}
+
+func (e *testCycleError) Error() string {
+	return "cycle"
+}
+
+func (e *testCycleError) Unwrap() error {
+	return e
+}
+
+func TestCycle(t *testing.T) {
+	err := &testVerboseError{
+		error: &testCycleError{},
+	}
+	s := String(err)
+	assert.Equal(t, s, "cycle\nverbose\n<cycle detected>\n")
+}
+
+func TestTruncated(t *testing.T) {
+	previous := erriter.DefaultLimits.Swap(erriter.Limits{MaxNodes: 2})
+	defer erriter.DefaultLimits.Store(previous)
+	err := std_errors.Join(
+		errbase.New("error a"),
+		errbase.New("error b"),
+	)
+	s := String(err)
+	assert.Equal(t, s, "error a\nerror b\n\nSub error 0: error a\n\nSub error 1: <truncated>\n")
+}
+
 func TestWriteAllocs(t *testing.T) {
 	err := errbase.New("error")
 	err = &testVerboseError{
@@ -181,3 +212,334 @@ func BenchmarkWrite(b *testing.B) {
 		Write(io.Discard, err)
 	}
 }
+
+func ExampleWriteTree() {
+	err := std_errors.Join(
+		errbase.New("error a"),
+		errbase.New("error b"),
+	)
+	err = &testVerboseError{
+		error: err,
+	}
+	buf := new(strings.Builder)
+	WriteTree(buf, err, TreeOptions{Collapse: true})
+	fmt.Print(buf.String())
+	// Output:
+	// error a
+	// │  error b
+	// │  verbose
+	// ├─ error a
+	// └─ error b
+}
+
+func newTestTreeError() error {
+	return &testVerboseError{
+		error: std_errors.Join(
+			&testAppendVerboseError{
+				error: errbase.New("error a"),
+			},
+			std_errors.Join(
+				errbase.New("error b"),
+				errbase.New("error c"),
+			),
+		),
+	}
+}
+
+func TestTreeString(t *testing.T) {
+	err := newTestTreeError()
+	s := TreeString(err, TreeOptions{})
+	expected := `error a
+│  error b
+│  error c
+│  verbose
+└─ error a
+   │  error b
+   │  error c
+   ├─ error a
+   │  │  verbose append
+   │  └─ error a
+   └─ error b
+      │  error c
+      ├─ error b
+      └─ error c
+`
+	assert.Equal(t, s, expected)
+}
+
+func TestTreeStringMaxJoinErrors(t *testing.T) {
+	previous := DefaultLimits.Swap(Limits{MaxJoinErrors: 1})
+	defer DefaultLimits.Store(previous)
+	err := std_errors.Join(
+		errbase.New("error a"),
+		errbase.New("error b"),
+		errbase.New("error c"),
+	)
+	s := TreeString(err, TreeOptions{})
+	expected := `error a
+│  error b
+│  error c
+├─ error a
+└─ ... and 2 more errors
+`
+	assert.Equal(t, s, expected)
+}
+
+func TestTreeStringMaxBytes(t *testing.T) {
+	previous := DefaultLimits.Swap(Limits{MaxBytes: 30})
+	defer DefaultLimits.Store(previous)
+	err := std_errors.Join(
+		errbase.New("error a"),
+		errbase.New("error b"),
+	)
+	s := TreeString(err, TreeOptions{})
+	expected := `error a
+│  error b
+... (truncated, 36 bytes)
+`
+	assert.Equal(t, s, expected)
+}
+
+func TestTreeStringCollapse(t *testing.T) {
+	err := newTestTreeError()
+	s := TreeString(err, TreeOptions{Collapse: true})
+	expected := `error a
+│  error b
+│  error c
+│  verbose
+├─ error a
+│     verbose append
+└─ error b
+   │  error c
+   ├─ error b
+   └─ error c
+`
+	assert.Equal(t, s, expected)
+}
+
+func TestTreeStringNil(t *testing.T) {
+	s := TreeString(nil, TreeOptions{})
+	assert.Equal(t, s, "<nil>\n")
+}
+
+func TestTreeStringCycle(t *testing.T) {
+	err := &testVerboseError{
+		error: &testCycleError{},
+	}
+	s := TreeString(err, TreeOptions{})
+	assert.Equal(t, s, "cycle\n│  verbose\n└─ cycle\n   └─ <cycle detected>\n")
+	s = TreeString(err, TreeOptions{Collapse: true})
+	assert.Equal(t, s, "cycle\n   verbose\n   <cycle detected>\n")
+}
+
+func TestTreeStringTruncated(t *testing.T) {
+	previous := erriter.DefaultLimits.Swap(erriter.Limits{MaxNodes: 2})
+	defer erriter.DefaultLimits.Store(previous)
+	err := std_errors.Join(
+		errbase.New("error a"),
+		errbase.New("error b"),
+	)
+	s := TreeString(err, TreeOptions{Collapse: true})
+	assert.Equal(t, s, "error a\n│  error b\n├─ error a\n└─ <truncated>\n")
+}
+
+func BenchmarkWriteTree(b *testing.B) {
+	err := newTestTreeError()
+	for b.Loop() {
+		WriteTree(io.Discard, err, TreeOptions{})
+	}
+}
+
+func TestAppendVerbose(t *testing.T) {
+	err := errbase.New("error")
+	b, ok := AppendVerbose(nil, err)
+	assert.False(t, ok)
+	assert.SliceLen(t, b, 0)
+	b, ok = AppendVerbose(nil, &testVerboseError{error: err})
+	assert.True(t, ok)
+	assert.Equal(t, string(b), "verbose")
+	b, ok = AppendVerbose(nil, &testAppendVerboseError{error: err})
+	assert.True(t, ok)
+	assert.Equal(t, string(b), "verbose append")
+}
+
+type testFormatError struct {
+	error
+}
+
+func (e *testFormatError) Unwrap() error {
+	return e.error
+}
+
+func (e *testFormatError) Format(s fmt.State, verb rune) {
+	Format(s, verb, e)
+}
+
+func TestFormat(t *testing.T) {
+	err := &testFormatError{
+		error: &testVerboseError{
+			error: errbase.New("error"),
+		},
+	}
+	assert.Equal(t, fmt.Sprintf("%v", err), "error")
+	assert.Equal(t, fmt.Sprintf("%s", err), "error") //nolint:gosimple // Test the verb.
+	assert.Equal(t, fmt.Sprintf("%q", err), `"error"`)
+	assert.Equal(t, fmt.Sprintf("%+v", err), String(err))
+	assert.Equal(t, fmt.Sprintf("%x", err), "6572726f72")
+	assert.Equal(t, fmt.Sprintf("%10.3s", err), "       err")
+	assert.Equal(t, fmt.Sprintf("%-8v", err), "error   ")
+	assert.Equal(t, fmt.Sprintf("%d", err), "%!d(string=error)")
+}
+
+func TestFormatGoSyntax(t *testing.T) {
+	err := &testFormatError{
+		error: std_errors.Join(
+			&testVerboseError{
+				error: errbase.New("error a"),
+			},
+			errbase.New("error b"),
+		),
+	}
+	s := fmt.Sprintf("%#v", err)
+	assert.Equal(t, s, `*errverbose_test.testFormatError{Error: "error a\nerror b", Unwrap: *errors.joinError{Error: "error a\nerror b", Unwrap: []error{*errverbose_test.testVerboseError{Error: "error a", Verbose: "verbose", Unwrap: *errors.errorString{Error: "error a"}}, *errors.errorString{Error: "error b"}}}}`)
+}
+
+func TestFormatGoSyntaxCycle(t *testing.T) {
+	err := &testFormatError{
+		error: &testCycleError{},
+	}
+	s := fmt.Sprintf("%#v", err)
+	assert.Equal(t, s, `*errverbose_test.testFormatError{Error: "cycle", Unwrap: *errverbose_test.testCycleError{Error: "cycle", Unwrap: <cycle detected>}}`)
+}
+
+func TestFormatGoSyntaxTruncated(t *testing.T) {
+	previous := erriter.DefaultLimits.Swap(erriter.Limits{MaxDepth: 1})
+	defer erriter.DefaultLimits.Store(previous)
+	err := &testFormatError{
+		error: errbase.New("error"),
+	}
+	s := fmt.Sprintf("%#v", err)
+	assert.Equal(t, s, `*errverbose_test.testFormatError{Error: "error", Unwrap: <truncated>}`)
+}
+
+func TestFormatGoSyntaxMaxJoinErrors(t *testing.T) {
+	previous := DefaultLimits.Swap(Limits{MaxJoinErrors: 1})
+	defer DefaultLimits.Store(previous)
+	err := &testFormatError{
+		error: std_errors.Join(
+			errbase.New("error a"),
+			errbase.New("error b"),
+			errbase.New("error c"),
+		),
+	}
+	s := fmt.Sprintf("%#v", err)
+	assert.Equal(t, s, `*errverbose_test.testFormatError{Error: "error a\nerror b\nerror c", Unwrap: *errors.joinError{Error: "error a\nerror b\nerror c", Unwrap: []error{*errors.errorString{Error: "error a"}, ... and 2 more errors}}}`)
+}
+
+func TestFormatGoSyntaxMaxBytes(t *testing.T) {
+	previous := DefaultLimits.Swap(Limits{MaxBytes: 50})
+	defer DefaultLimits.Store(previous)
+	err := &testFormatError{
+		error: std_errors.Join(
+			errbase.New("error a"),
+			errbase.New("error b"),
+		),
+	}
+	s := fmt.Sprintf("%#v", err)
+	assert.Equal(t, s, `*errverbose_test.testFormatError{Error: "error a\n ... (truncated, 59 bytes)`)
+}
+
+func TestWriteLimitsMaxJoinErrors(t *testing.T) {
+	errs := make([]error, 12345)
+	for i := range errs {
+		errs[i] = errbase.New("error")
+	}
+	err := std_errors.Join(errs...)
+	buf := new(strings.Builder)
+	WriteLimits(buf, err, Limits{MaxJoinErrors: 2})
+	s := buf.String()
+	assert.StringHasSuffix(t, s, "\n\nSub error 0: error\n\nSub error 1: error\n\n... and 12,343 more errors\n")
+}
+
+func TestWriteLimitsMaxBytes(t *testing.T) {
+	err := errbase.New("éééééééééé")
+	buf := new(strings.Builder)
+	WriteLimits(buf, err, Limits{MaxBytes: 5})
+	s := buf.String()
+	assert.Equal(t, s, "éé\n... (truncated, 21 bytes)\n")
+}
+
+func TestWriteLimitsMaxBytesStop(t *testing.T) {
+	err := std_errors.Join(
+		errbase.New("error a"),
+		errbase.New("error b"),
+	)
+	buf := new(strings.Builder)
+	WriteLimits(buf, err, Limits{MaxBytes: 20})
+	s := buf.String()
+	assert.Equal(t, s, "error a\nerror b\n\nSub\n... (truncated, 38 bytes)\n")
+}
+
+var testStyle = &Style{
+	Message: func(b []byte, msg []byte) []byte {
+		return fmt.Appendf(b, "<m>%s</m>", msg)
+	},
+	Verbose: func(b []byte, err error, verbose []byte) []byte {
+		return fmt.Appendf(b, "<v>%s</v>", verbose)
+	},
+	Note: func(b []byte, note []byte) []byte {
+		return fmt.Appendf(b, "<n>%s</n>", note)
+	},
+}
+
+func TestWriteStyle(t *testing.T) {
+	err := errbase.New("error")
+	err = errtag.Wrap(err, "foo", "bar")
+	err = std_errors.Join(err, errbase.New("other"))
+	buf := new(strings.Builder)
+	WriteStyle(buf, err, Limits{MaxJoinErrors: 1}, testStyle)
+	s := buf.String()
+	assert.Equal(t, s, "<m>error\nother</m>\n\nSub error 0: <m>error</m>\n<v>tag foo = bar</v>\n\n<n>... and 1 more error</n>\n")
+}
+
+func TestWriteStyleMaxBytes(t *testing.T) {
+	err := std_errors.Join(
+		errbase.New("error a"),
+		errbase.New("error b"),
+	)
+	buf := new(strings.Builder)
+	WriteStyle(buf, err, Limits{MaxBytes: 35}, testStyle)
+	s := buf.String()
+	assert.Equal(t, s, "<m>error a\nerror b</m>\n\n\n<n>... (truncated, 52 bytes)</n>\n")
+}
+
+func TestWriteStyleNilFunctions(t *testing.T) {
+	err := errtag.Wrap(errbase.New("error"), "foo", "bar")
+	buf := new(strings.Builder)
+	WriteStyle(buf, err, Limits{}, &Style{})
+	assert.Equal(t, buf.String(), String(err))
+}
+
+func TestWriteDefaultLimits(t *testing.T) {
+	previous := DefaultLimits.Swap(Limits{MaxBytes: 3})
+	defer DefaultLimits.Store(previous)
+	s := String(errbase.New("error"))
+	assert.Equal(t, s, "err\n... (truncated, 6 bytes)\n")
+}
+
+func TestAppendCount(t *testing.T) {
+	for _, tc := range []struct {
+		n        int
+		expected string
+	}{
+		{0, "0"},
+		{12, "12"},
+		{999, "999"},
+		{1000, "1,000"},
+		{9990, "9,990"},
+		{1234567, "1,234,567"},
+		{-1234, "-1,234"},
+	} {
+		assert.Equal(t, string(AppendCount(nil, tc.n)), tc.expected)
+	}
+}
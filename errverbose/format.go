@@ -0,0 +1,130 @@
+package errverbose
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/pierrre/errors/erriter"
+	"github.com/pierrre/go-libs/bytesutil"
+)
+
+// Format implements [fmt.Formatter] for an error.
+//
+// It is used by the error types of this module, and can be used by custom error types:
+//   - %+v writes the error's verbose message (see [Write])
+//   - %#v writes a Go-syntax-like representation of the error tree, limited by [DefaultLimits]
+//   - the other verbs, flags, width and precision are applied to the error's message, as [fmt] does for an error without a Format method
+func Format(s fmt.State, verb rune, err error) {
+	if verb != 'v' || (!s.Flag('+') && !s.Flag('#')) {
+		_, _ = fmt.Fprintf(s, fmt.FormatString(s, verb), err.Error())
+		return
+	}
+	bw := bytesWriterPool.Get()
+	defer bytesWriterPool.Put(bw)
+	if s.Flag('+') {
+		write(bw, err, DefaultLimits.Load())
+	} else {
+		writeGoSyntax(bw, err, DefaultLimits.Load())
+	}
+	_, _ = s.Write(*bw)
+}
+
+// writeGoSyntax writes the error tree, with the type, message and verbose message of each error.
+//
+// The error tree is traversed with [erriter.Walk].
+// The errors that are part of a cycle are replaced with "<cycle detected>", and the errors that exceed the limits are replaced with "<truncated>".
+// The output is limited by the given [Limits].
+func writeGoSyntax(bw *bytesutil.Writer, err error, limits Limits) {
+	if err == nil {
+		bw.AppendString("<nil>")
+		return
+	}
+	g := &goSyntaxWriter{
+		bw:     bw,
+		limits: limits,
+		start:  len(*bw),
+	}
+	erriter.Walk(err, g.node)
+	g.close(0)
+	total, ok := truncateBytes(bw, g.start, limits.MaxBytes, false)
+	if ok {
+		bw.AppendByte(' ')
+		*bw = appendTruncated(*bw, total)
+	}
+}
+
+type goSyntaxWriter struct {
+	bw     *bytesutil.Writer
+	limits Limits
+	start  int
+	open   []goSyntaxNode // The errors whose closing brace is not written yet.
+}
+
+type goSyntaxNode struct {
+	level    int
+	join     bool // The error wraps joined errors (`Unwrap() []error`).
+	errs     int  // The number of joined errors.
+	children int  // The number of visited wrapped errors.
+}
+
+func (g *goSyntaxWriter) node(n erriter.Node) erriter.WalkAction {
+	if g.limits.MaxBytes > 0 && len(*g.bw)-g.start > g.limits.MaxBytes {
+		return erriter.WalkStop
+	}
+	g.close(n.Level)
+	if len(g.open) > 0 {
+		parent := &g.open[len(g.open)-1]
+		parent.children++
+		switch {
+		case !parent.join:
+			g.bw.AppendString(", Unwrap: ")
+		case g.limits.MaxJoinErrors > 0 && parent.children > g.limits.MaxJoinErrors:
+			if parent.children == g.limits.MaxJoinErrors+1 {
+				g.bw.AppendString(", ")
+				*g.bw = AppendMoreErrors(*g.bw, parent.errs-g.limits.MaxJoinErrors)
+			}
+			return erriter.WalkSkip
+		case parent.children > 1:
+			g.bw.AppendString(", ")
+		}
+	}
+	switch {
+	case n.Cycle:
+		g.bw.AppendString("<cycle detected>")
+		return erriter.WalkSkip
+	case n.Truncated:
+		g.bw.AppendString("<truncated>")
+		return erriter.WalkSkip
+	}
+	g.bw.AppendString(reflect.TypeOf(n.Err).String())
+	g.bw.AppendString("{Error: ")
+	*g.bw = strconv.AppendQuote(*g.bw, string(AppendMessageLimits(nil, n.Err, g.limits)))
+	verbose, ok := appendVerbose(nil, n.Err, g.limits)
+	if ok {
+		g.bw.AppendString(", Verbose: ")
+		*g.bw = strconv.AppendQuote(*g.bw, string(verbose))
+	}
+	errs, _ := erriter.Unwrap(n.Err)
+	gn := goSyntaxNode{
+		level: n.Level,
+		join:  errs != nil,
+		errs:  len(errs),
+	}
+	if gn.join {
+		g.bw.AppendString(", Unwrap: []error{")
+	}
+	g.open = append(g.open, gn)
+	return erriter.WalkContinue
+}
+
+// close writes the closing braces of the open errors with a level greater than or equal to the given level.
+func (g *goSyntaxWriter) close(level int) {
+	for len(g.open) > 0 && g.open[len(g.open)-1].level >= level {
+		if g.open[len(g.open)-1].join {
+			g.bw.AppendByte('}')
+		}
+		g.bw.AppendByte('}')
+		g.open = g.open[:len(g.open)-1]
+	}
+}
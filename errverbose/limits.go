@@ -0,0 +1,126 @@
+package errverbose
+
+import (
+	"bytes"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/pierrre/errors/errappend"
+	"github.com/pierrre/go-libs/bytesutil"
+	"github.com/pierrre/go-libs/syncutil/atomicutil"
+)
+
+// Limits are the limits applied to a verbose message.
+//
+// They protect against very large messages, e.g. a joined error with thousands of errors.
+// 0 means no limit.
+type Limits struct {
+	// MaxBytes is the maximum number of bytes of the verbose message.
+	// The message is truncated if it exceeds it.
+	MaxBytes int
+	// MaxJoinErrors is the maximum number of sub errors shown per joined error.
+	// It also limits the message of a joined error written by the renderers (see [MessageLimitsAppendInterface]).
+	MaxJoinErrors int
+	// MaxValueLength is the maximum number of bytes of a value representation (see the errval package).
+	MaxValueLength int
+	// MaxStackFrames is the maximum number of frames of a stack (see the errstack package).
+	MaxStackFrames int
+}
+
+// DefaultLimits are the [Limits] used by [Write] and the other functions of this package.
+//
+// The default value is a MaxBytes of 1 MiB, a MaxJoinErrors of 100, a MaxValueLength of 10000 and a MaxStackFrames of 100.
+var DefaultLimits atomicutil.Value[Limits]
+
+func init() {
+	DefaultLimits.Store(Limits{
+		MaxBytes:       1 << 20,
+		MaxJoinErrors:  100,
+		MaxValueLength: 10000,
+		MaxStackFrames: 100,
+	})
+}
+
+// LimitsAppendInterface is like [AppendInterface], but the verbose message is limited by the given [Limits].
+//
+// It is preferred over [Interface] and [AppendInterface].
+type LimitsAppendInterface interface {
+	error
+	ErrorVerboseAppendLimits(b []byte, limits Limits) []byte
+}
+
+// MessageLimitsAppendInterface is an error that appends a message limited by the given [Limits].
+//
+// It is used by the renderers of this package (and errcolor and errreport) instead of the error's message, e.g. to limit the number of joined messages with [Limits.MaxJoinErrors].
+// The error's message (Error) is not limited.
+type MessageLimitsAppendInterface interface {
+	error
+	ErrorAppendLimits(b []byte, limits Limits) []byte
+}
+
+// AppendMessageLimits appends the error's message limited by the given [Limits] to b.
+//
+// It uses [MessageLimitsAppendInterface] if the error implements it, otherwise it appends the error's message (see the errappend package).
+func AppendMessageLimits(b []byte, err error, limits Limits) []byte {
+	if v, ok := err.(MessageLimitsAppendInterface); ok { //nolint:errorlint // We want to check the current error.
+		return v.ErrorAppendLimits(b, limits)
+	}
+	return errappend.Append(b, err)
+}
+
+// AppendMoreErrors appends "... and N more errors" to b.
+//
+// It is the note written in place of the sub errors that exceed [Limits.MaxJoinErrors].
+func AppendMoreErrors(b []byte, n int) []byte {
+	b = append(b, "... and "...)
+	b = AppendCount(b, n)
+	if n == 1 {
+		return append(b, " more error"...)
+	}
+	return append(b, " more errors"...)
+}
+
+// truncateBytes truncates the content written after start to maxBytes.
+//
+// If lines is true, it is truncated at a line boundary, otherwise it is truncated without splitting a UTF-8 sequence.
+// It returns the size of the content before the truncation, and false if it doesn't exceed maxBytes (0 means no limit).
+func truncateBytes(bw *bytesutil.Writer, start int, maxBytes int, lines bool) (int, bool) {
+	total := len(*bw) - start
+	if maxBytes <= 0 || total <= maxBytes {
+		return total, false
+	}
+	end := start + maxBytes
+	if lines {
+		end = start + bytes.LastIndexByte((*bw)[start:end], '\n') + 1
+	} else {
+		for end > start && !utf8.RuneStart((*bw)[end]) {
+			end--
+		}
+	}
+	*bw = (*bw)[:end]
+	return total, true
+}
+
+// appendTruncated appends "... (truncated, N bytes)" to b.
+func appendTruncated(b []byte, total int) []byte {
+	b = append(b, "... (truncated, "...)
+	b = AppendCount(b, total)
+	return append(b, " bytes)"...)
+}
+
+// AppendCount appends a count with a thousands separator (e.g. "9,990") to b.
+func AppendCount(b []byte, n int) []byte {
+	if n < 0 {
+		b = append(b, '-')
+		n = -n
+	}
+	var buf [24]byte
+	s := strconv.AppendInt(buf[:0], int64(n), 10)
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b = append(b, ',')
+		}
+		b = append(b, c)
+	}
+	return b
+}
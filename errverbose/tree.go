@@ -0,0 +1,207 @@
+package errverbose
+
+import (
+	"bytes"
+	"io"
+	"slices"
+
+	"github.com/pierrre/errors/erriter"
+	"github.com/pierrre/go-libs/bytesutil"
+)
+
+// TreeOptions are the options of [WriteTree].
+type TreeOptions struct {
+	// Collapse collapses the chains of wrapper errors with a single child into a single node.
+	// The node shows the message of the outermost error, and the verbose lines of all the errors of the chain.
+	Collapse bool
+}
+
+// WriteTree writes the error's verbose message to the writer, as a tree drawn with box-drawing characters.
+//
+// Each node shows the error's message, followed by its verbose lines indented under it.
+// The children of a node are the errors that it wraps.
+//
+// The error tree is traversed with [erriter.Walk].
+// The errors that are part of a cycle are replaced with "<cycle detected>", and the errors that exceed the limits are replaced with "<truncated>".
+//
+// The output is limited by [DefaultLimits], like [Write].
+func WriteTree(w io.Writer, err error, opts TreeOptions) {
+	bw, ok := w.(*bytesutil.Writer)
+	if !ok {
+		bw = bytesWriterPool.Get()
+		defer func() {
+			_, _ = w.Write(*bw)
+			bytesWriterPool.Put(bw)
+		}()
+	}
+	writeTree(bw, err, opts)
+}
+
+// TreeString returns the error's verbose message as a tree (see [WriteTree]).
+func TreeString(err error, opts TreeOptions) string {
+	bw := bytesWriterPool.Get()
+	defer bytesWriterPool.Put(bw)
+	WriteTree(bw, err, opts)
+	return bw.String()
+}
+
+const (
+	treeBranch     = "├─ "
+	treeLastBranch = "└─ "
+	treeLine       = "│  "
+	treeSpace      = "   "
+)
+
+type treeNode struct {
+	errs   []error
+	marker string
+	level  int
+	next   bool // The node has a next sibling.
+}
+
+func writeTree(bw *bytesutil.Writer, err error, opts TreeOptions) {
+	if err == nil {
+		bw.AppendString("<nil>\n")
+		return
+	}
+	limits := DefaultLimits.Load()
+	nodes := collectTreeNodes(err, opts, limits)
+	setTreeNodesNext(nodes)
+	tmp := bytesWriterPool.Get()
+	defer bytesWriterPool.Put(tmp)
+	start := len(*bw)
+	var ancestors []bool // The next field of the ancestors, excluding the root.
+	var prefix []byte
+	for i, n := range nodes {
+		if limits.MaxBytes > 0 && len(*bw)-start > limits.MaxBytes {
+			break
+		}
+		ancestors = ancestors[:max(n.level-1, 0)]
+		prefix = prefix[:0]
+		for _, next := range ancestors {
+			prefix = appendTreeIndent(prefix, next)
+		}
+		children := i+1 < len(nodes) && nodes[i+1].level > n.level
+		writeTreeNode(bw, tmp, prefix, n, children, limits)
+		if n.level > 0 {
+			ancestors = append(ancestors, n.next)
+		}
+	}
+	total, ok := truncateBytes(bw, start, limits.MaxBytes, true)
+	if ok {
+		*bw = appendTruncated(*bw, total)
+		bw.AppendByte('\n')
+	}
+}
+
+func collectTreeNodes(err error, opts TreeOptions, limits Limits) []treeNode {
+	var nodes []treeNode
+	erriter.Walk(err, func(n erriter.Node) erriter.WalkAction {
+		level := n.Level
+		if opts.Collapse {
+			level = len(n.Path)
+		}
+		if n.Depth == 0 && len(n.Path) > 0 && limits.MaxJoinErrors > 0 {
+			i := n.Path[len(n.Path)-1]
+			if i >= limits.MaxJoinErrors {
+				if i == limits.MaxJoinErrors {
+					errs, _ := erriter.Unwrap(n.Parent)
+					nodes = append(nodes, treeNode{
+						marker: string(AppendMoreErrors(nil, len(errs)-i)),
+						level:  level,
+					})
+				}
+				return erriter.WalkSkip
+			}
+		}
+		if !opts.Collapse || n.Depth == 0 {
+			nodes = append(nodes, treeNode{
+				level: level,
+			})
+		}
+		tn := &nodes[len(nodes)-1]
+		switch {
+		case n.Cycle:
+			tn.marker = "<cycle detected>"
+			return erriter.WalkSkip
+		case n.Truncated:
+			tn.marker = "<truncated>"
+			return erriter.WalkSkip
+		}
+		tn.errs = append(tn.errs, n.Err)
+		return erriter.WalkContinue
+	})
+	return nodes
+}
+
+// setTreeNodesNext sets the next field of the nodes.
+//
+// The nodes are in pre-order, so a node has a next sibling if the first following node with a lower or equal level has the same level.
+func setTreeNodesNext(nodes []treeNode) {
+	var levels []bool // Indexed by level, true if a node with this level follows.
+	for i := len(nodes) - 1; i >= 0; i-- {
+		n := &nodes[i]
+		levels = levels[:min(len(levels), n.level+1)]
+		n.next = n.level < len(levels) && levels[n.level]
+		for len(levels) <= n.level {
+			levels = append(levels, false)
+		}
+		levels[n.level] = true
+	}
+}
+
+func writeTreeNode(bw *bytesutil.Writer, tmp *bytesutil.Writer, prefix []byte, n treeNode, children bool, limits Limits) {
+	bw.Append(prefix)
+	if n.level > 0 {
+		if n.next {
+			bw.AppendString(treeBranch)
+		} else {
+			bw.AppendString(treeLastBranch)
+		}
+	}
+	bodyPrefix := slices.Clip(prefix)
+	if n.level > 0 {
+		bodyPrefix = appendTreeIndent(bodyPrefix, n.next)
+	}
+	bodyPrefix = appendTreeIndent(bodyPrefix, children)
+	tmp.Reset()
+	if len(n.errs) > 0 {
+		*tmp = AppendMessageLimits(*tmp, n.errs[0], limits)
+	} else {
+		tmp.AppendString(n.marker)
+	}
+	first, rest, _ := bytes.Cut(*tmp, []byte("\n"))
+	bw.Append(first)
+	bw.AppendByte('\n')
+	writeTreeLines(bw, bodyPrefix, rest)
+	for _, err := range n.errs {
+		tmp.Reset()
+		writeVerbose(tmp, err, limits)
+		writeTreeLines(bw, bodyPrefix, *tmp)
+	}
+	if len(n.errs) > 0 && n.marker != "" {
+		writeTreeLines(bw, bodyPrefix, []byte(n.marker))
+	}
+}
+
+// writeTreeLines writes the lines of b with the prefix.
+// Empty lines are skipped, because they would break the tree.
+func writeTreeLines(bw *bytesutil.Writer, prefix []byte, b []byte) {
+	for line := range bytes.Lines(b) {
+		if len(line) == 1 && line[0] == '\n' {
+			continue
+		}
+		bw.Append(prefix)
+		bw.Append(line)
+	}
+	if len(b) > 0 && b[len(b)-1] != '\n' {
+		bw.AppendByte('\n')
+	}
+}
+
+func appendTreeIndent(b []byte, line bool) []byte {
+	if line {
+		return append(b, treeLine...)
+	}
+	return append(b, treeSpace...)
+}
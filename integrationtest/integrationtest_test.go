@@ -1,15 +1,23 @@
 package integrationtest
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"iter"
+	"log/slog"
 	"runtime"
 	"slices"
 	"testing"
 
 	"github.com/pierrre/assert"
 	"github.com/pierrre/errors"
+	"github.com/pierrre/errors/errbase"
+	"github.com/pierrre/errors/errctx"
 	"github.com/pierrre/errors/errignore"
+	"github.com/pierrre/errors/errjoin"
+	"github.com/pierrre/errors/errmsg"
+	"github.com/pierrre/errors/errslog"
 	"github.com/pierrre/errors/errstack"
 	"github.com/pierrre/errors/errtag"
 	"github.com/pierrre/errors/errtmp"
@@ -77,11 +85,45 @@ func TestValue(t *testing.T) {
 	assert.MapEqual(t, values, map[string]any{"c": "d"})
 }
 
+func TestFormat(t *testing.T) {
+	base := errbase.New("error")
+	ctx, cancel := errctx.WithCancelCause(context.Background())
+	cancel(base)
+	for _, tc := range []struct {
+		name string
+		err  error
+	}{
+		{name: "Msg", err: errmsg.Wrap(base, "test")},
+		{name: "Stack", err: errstack.Wrap(base)},
+		{name: "Tag", err: errtag.Wrap(base, "a", "b")},
+		{name: "Value", err: errval.Wrap(base, "c", "d")},
+		{name: "Temporary", err: errtmp.Wrap(base, true)},
+		{name: "Ignore", err: errignore.Wrap(base)},
+		{name: "SlogAttrs", err: errslog.WrapAttrs(base, slog.String("e", "f"))},
+		{name: "SlogLevel", err: errslog.WrapLevel(base, slog.LevelWarn)},
+		{name: "Join", err: errjoin.Join(base, base)},
+		{name: "Context", err: errctx.Err(ctx)},
+		{name: "All", err: newTestError()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _ = assert.Type[fmt.Formatter](t, tc.err)
+			assert.Equal(t, fmt.Sprintf("%v", tc.err), tc.err.Error())
+			assert.Equal(t, fmt.Sprintf("%s", tc.err), tc.err.Error()) //nolint:gosimple // Test the verb.
+			assert.Equal(t, fmt.Sprintf("%q", tc.err), fmt.Sprintf("%q", tc.err.Error()))
+			assert.Equal(t, fmt.Sprintf("%+v", tc.err), errverbose.String(tc.err))
+			assert.StringHasPrefix(t, fmt.Sprintf("%#v", tc.err), "*")
+			assert.Equal(t, fmt.Sprintf("%x", tc.err), fmt.Sprintf("%x", tc.err.Error()))
+			assert.Equal(t, fmt.Sprintf("%10.3s", tc.err), fmt.Sprintf("%10.3s", tc.err.Error()))
+			assert.Equal(t, fmt.Sprintf("%-8v", tc.err), fmt.Sprintf("%-8v", tc.err.Error()))
+		})
+	}
+}
+
 func TestNewAllocs(t *testing.T) {
 	var res error
 	assert.AllocsPerRun(t, 100, func() {
 		res = newTestError()
-	}, 15)
+	}, 10)
 	testSink = res
 }
 
@@ -98,7 +140,7 @@ func TestVerboseAllocs(t *testing.T) {
 	err := newTestError()
 	assert.AllocsPerRun(t, 100, func() {
 		errverbose.Write(io.Discard, err)
-	}, 3)
+	}, 0)
 }
 
 func TestStackAllocs(t *testing.T) {
@@ -115,7 +157,7 @@ func TestIgnoreAllocs(t *testing.T) {
 	var res bool
 	assert.AllocsPerRun(t, 100, func() {
 		res = errignore.Is(err)
-	}, 1)
+	}, 0)
 	testSink = res
 }
 
@@ -124,7 +166,7 @@ func TestTemporaryAllocs(t *testing.T) {
 	var res bool
 	assert.AllocsPerRun(t, 100, func() {
 		res = errtmp.Is(err)
-	}, 1)
+	}, 0)
 	testSink = res
 }
 
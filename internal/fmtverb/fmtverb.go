@@ -0,0 +1,179 @@
+// Package fmtverb parses the verbs of [fmt] format strings.
+package fmtverb
+
+import (
+	"iter"
+	"strings"
+	"unicode/utf8"
+)
+
+// Verb is a verb of a format string.
+type Verb struct {
+	// Start is the index of the '%' character.
+	Start int
+	// End is the index after the verb character.
+	End int
+	// Verb is the verb character.
+	// It is 0 if the format ends before the verb.
+	Verb rune
+	// Indexed is true if the verb uses an explicit argument index, such as "%[1]w".
+	Indexed bool
+}
+
+// Next returns the first verb of the format starting at index i.
+//
+// Escaped percent signs ("%%") are not verbs.
+// It returns false if there is no verb.
+func Next(format string, i int) (Verb, bool) {
+	for {
+		v, ok := next(format, i)
+		if !ok {
+			return Verb{}, false
+		}
+		if v.Verb != '%' {
+			return v, true
+		}
+		i = v.End
+	}
+}
+
+// next returns the next verb, including escaped percent signs.
+//
+// It follows the parsing rules of [fmt.Printf]: flags, argument index, width, precision and verb.
+func next(format string, i int) (Verb, bool) {
+	for i < len(format) && format[i] != '%' {
+		i++
+	}
+	if i >= len(format) {
+		return Verb{}, false
+	}
+	v := Verb{
+		Start: i,
+	}
+	i++
+	for i < len(format) && isFlag(format[i]) {
+		i++
+	}
+	i = skipArgIndex(format, i, &v)
+	i = skipNumber(format, i)
+	if i < len(format) && format[i] == '.' {
+		i++
+		i = skipArgIndex(format, i, &v)
+		i = skipNumber(format, i)
+	}
+	i = skipArgIndex(format, i, &v)
+	if i < len(format) {
+		r, size := utf8.DecodeRuneInString(format[i:])
+		v.Verb = r
+		i += size
+	}
+	v.End = i
+	return v, true
+}
+
+func isFlag(c byte) bool {
+	switch c {
+	case '#', '0', '+', '-', ' ':
+		return true
+	}
+	return false
+}
+
+func skipArgIndex(format string, i int, v *Verb) int {
+	if i >= len(format) || format[i] != '[' {
+		return i
+	}
+	v.Indexed = true
+	for j := i + 1; j < len(format); j++ {
+		if format[j] == ']' {
+			return j + 1
+		}
+	}
+	return i + 1
+}
+
+func skipNumber(format string, i int) int {
+	if i < len(format) && format[i] == '*' {
+		return i + 1
+	}
+	for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+		i++
+	}
+	return i
+}
+
+// All returns an iterator over the verbs of the format.
+//
+// Escaped percent signs ("%%") are not verbs.
+func All(format string) iter.Seq[Verb] {
+	return func(yield func(Verb) bool) {
+		for i := 0; ; {
+			v, ok := Next(format, i)
+			if !ok || !yield(v) {
+				return
+			}
+			i = v.End
+		}
+	}
+}
+
+// Has returns true if the format contains the verb.
+func Has(format string, verb rune) bool {
+	for i := 0; ; {
+		v, ok := Next(format, i)
+		if !ok {
+			return false
+		}
+		if v.Verb == verb {
+			return true
+		}
+		i = v.End
+	}
+}
+
+// Unescape returns the format with escaped percent signs replaced by "%".
+//
+// It returns false if the format contains a verb, because it can't be written without formatting.
+func Unescape(format string) (string, bool) {
+	var b []byte
+	last := 0
+	for i := 0; ; {
+		v, ok := next(format, i)
+		if !ok {
+			break
+		}
+		if v.Verb != '%' {
+			return "", false
+		}
+		b = append(b, format[last:v.Start]...)
+		b = append(b, '%')
+		last = v.End
+		i = v.End
+	}
+	if b == nil {
+		return format, true
+	}
+	b = append(b, format[last:]...)
+	return string(b), true
+}
+
+// CutWrapSuffix returns the format without its ": %w" suffix.
+//
+// It returns false if the format doesn't end with ": %w", or if it contains another %w verb or an explicit argument index.
+func CutWrapSuffix(format string) (string, bool) {
+	var last Verb
+	for v := range All(format) {
+		if v.Indexed || (v.Verb == 'w' && v.End != len(format)) {
+			return "", false
+		}
+		last = v
+	}
+	if last.Verb != 'w' || format[last.Start:] != "%w" {
+		return "", false
+	}
+	msg, ok := strings.CutSuffix(format[:last.Start], ": ")
+	if !ok {
+		return "", false
+	}
+	return msg, true
+}
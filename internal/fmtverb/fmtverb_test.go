@@ -0,0 +1,108 @@
+package fmtverb_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pierrre/assert"
+	. "github.com/pierrre/errors/internal/fmtverb"
+)
+
+func TestAll(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		format   string
+		expected []Verb
+	}{
+		{name: "Empty", format: ""},
+		{name: "NoVerb", format: "test"},
+		{name: "Escaped", format: "100%% done"},
+		{name: "Simple", format: "a %v b", expected: []Verb{{Start: 2, End: 4, Verb: 'v'}}},
+		{name: "Flags", format: "%+w", expected: []Verb{{Start: 0, End: 3, Verb: 'w'}}},
+		{name: "WidthPrecision", format: "%-8.3f", expected: []Verb{{Start: 0, End: 6, Verb: 'f'}}},
+		{name: "Star", format: "%*.*d", expected: []Verb{{Start: 0, End: 5, Verb: 'd'}}},
+		{name: "Indexed", format: "%[1]w", expected: []Verb{{Start: 0, End: 5, Verb: 'w', Indexed: true}}},
+		{name: "IndexedWidth", format: "%[2]*[1]d", expected: []Verb{{Start: 0, End: 9, Verb: 'd', Indexed: true}}},
+		{name: "EscapedW", format: "%%w", expected: nil},
+		{name: "EscapedThenVerb", format: "%%%w", expected: []Verb{{Start: 2, End: 4, Verb: 'w'}}},
+		{name: "Multiple", format: "%s: %w", expected: []Verb{{Start: 0, End: 2, Verb: 's'}, {Start: 4, End: 6, Verb: 'w'}}},
+		{name: "NoVerbAtEnd", format: "a %", expected: []Verb{{Start: 2, End: 3}}},
+		{name: "Unicode", format: "%é", expected: []Verb{{Start: 0, End: 3, Verb: 'é'}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			vs := slices.Collect(All(tc.format))
+			assert.DeepEqual(t, vs, tc.expected)
+		})
+	}
+}
+
+func TestHas(t *testing.T) {
+	for _, tc := range []struct {
+		format   string
+		expected bool
+	}{
+		{format: "test", expected: false},
+		{format: "%w", expected: true},
+		{format: "%[1]w", expected: true},
+		{format: "%+w", expected: true},
+		{format: "%v", expected: false},
+		{format: "%%w", expected: false},
+		{format: "%%%w", expected: true},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			assert.Equal(t, Has(tc.format, 'w'), tc.expected)
+		})
+	}
+}
+
+func TestUnescape(t *testing.T) {
+	for _, tc := range []struct {
+		format   string
+		expected string
+		ok       bool
+	}{
+		{format: "test", expected: "test", ok: true},
+		{format: "100%% done", expected: "100% done", ok: true},
+		{format: "%%%%", expected: "%%", ok: true},
+		{format: "%v", ok: false},
+		{format: "a %", ok: false},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			s, ok := Unescape(tc.format)
+			assert.Equal(t, ok, tc.ok)
+			assert.Equal(t, s, tc.expected)
+		})
+	}
+}
+
+func TestCutWrapSuffix(t *testing.T) {
+	for _, tc := range []struct {
+		format   string
+		expected string
+		ok       bool
+	}{
+		{format: "test: %w", expected: "test", ok: true},
+		{format: "test %s: %w", expected: "test %s", ok: true},
+		{format: "100%% done: %w", expected: "100%% done", ok: true},
+		{format: "a%%: %w", expected: "a%%", ok: true},
+		{format: "test: %%w", ok: false},
+		{format: "test %w", ok: false},
+		{format: "test: %+w", ok: false},
+		{format: "%w: test", ok: false},
+		{format: "a: %w, b: %w", ok: false},
+		{format: "%[1]s: %w", ok: false},
+		{format: "test", ok: false},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			s, ok := CutWrapSuffix(tc.format)
+			assert.Equal(t, ok, tc.ok)
+			assert.Equal(t, s, tc.expected)
+		})
+	}
+}
+
+func TestHasAllocs(t *testing.T) {
+	assert.AllocsPerRun(t, 100, func() {
+		Has("a %s %[1]w", 'w')
+	}, 0)
+}
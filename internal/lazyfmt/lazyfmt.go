@@ -0,0 +1,65 @@
+// Package lazyfmt provides lazily formatted messages.
+package lazyfmt
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MaxArgs is the maximum number of arguments of a [Message].
+const MaxArgs = 4
+
+// Supported returns true if the format and arguments can be formatted lazily.
+//
+// The format must not be empty, and there must be at most [MaxArgs] arguments.
+// The arguments must be nil or values of a basic type (bool, string, integer, float, complex), which can't be modified after the call.
+// Other types (pointers, slices, maps, structs, errors, etc.) could be modified before the message is formatted.
+func Supported(format string, args []any) bool {
+	if format == "" || len(args) > MaxArgs {
+		return false
+	}
+	for _, arg := range args {
+		switch arg.(type) {
+		case nil, bool, string,
+			int, int8, int16, int32, int64,
+			uint, uint8, uint16, uint32, uint64, uintptr,
+			float32, float64, complex64, complex128:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Message is a lazily formatted message.
+//
+// It is formatted the first time [Message.String] is called, and the result is cached.
+// It is safe for concurrent use.
+// It must not be copied after initialization.
+type Message struct {
+	once   sync.Once
+	format string
+	args   [MaxArgs]any
+	n      int
+	msg    string
+}
+
+// Init initializes the message.
+//
+// The arguments must be [Supported].
+// They are copied, so args can be reused by the caller.
+func (m *Message) Init(format string, args []any) {
+	m.format = format
+	m.n = copy(m.args[:], args)
+}
+
+// String returns the formatted message.
+func (m *Message) String() string {
+	m.once.Do(m.doFormat)
+	return m.msg
+}
+
+func (m *Message) doFormat() {
+	m.msg = fmt.Sprintf(m.format, m.args[:m.n]...)
+	m.args = [MaxArgs]any{} // Release the arguments.
+}
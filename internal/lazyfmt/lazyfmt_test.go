@@ -0,0 +1,53 @@
+package lazyfmt_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pierrre/assert"
+	"github.com/pierrre/errors/errbase"
+	. "github.com/pierrre/errors/internal/lazyfmt"
+)
+
+func TestSupported(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		format   string
+		args     []any
+		expected bool
+	}{
+		{name: "NoArgs", format: "test", expected: true},
+		{name: "Basic", format: "%v %v %v %v", args: []any{1, "a", true, 1.5}, expected: true},
+		{name: "Nil", format: "%v", args: []any{nil}, expected: true},
+		{name: "EmptyFormat", format: "", expected: false},
+		{name: "TooManyArgs", format: "%v", args: []any{1, 2, 3, 4, 5}, expected: false},
+		{name: "Pointer", format: "%v", args: []any{new(1)}, expected: false},
+		{name: "Slice", format: "%v", args: []any{[]int{1}}, expected: false},
+		{name: "Error", format: "%v", args: []any{errbase.New("error")}, expected: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, Supported(tc.format, tc.args), tc.expected)
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	args := []any{1, "a"}
+	m := new(Message)
+	m.Init("%d %s", args)
+	args[0] = 2
+	assert.Equal(t, m.String(), "1 a")
+	assert.Equal(t, m.String(), "1 a")
+}
+
+func TestMessageConcurrent(t *testing.T) {
+	m := new(Message)
+	m.Init("%d", []any{1})
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			assert.Equal(t, m.String(), "1")
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,126 @@
+package errors
+
+import (
+	"fmt"
+
+	"github.com/pierrre/errors/errappend"
+	"github.com/pierrre/errors/erriter"
+	"github.com/pierrre/errors/errmsg"
+	"github.com/pierrre/errors/errstack"
+	"github.com/pierrre/errors/errverbose"
+	"github.com/pierrre/errors/internal/lazyfmt"
+)
+
+// stackMessage is an error with a message and a stack, created with a single allocation.
+//
+// If err is nil, it is equivalent to errstack.Wrap(errbase.New(msg)).
+// Otherwise, it is equivalent to errmsg.Wrap(errstack.Wrap(err), msg).
+//
+// The observable behavior is the same: the message, the verbose message, [errstack.Frames], [Is] and [As].
+// The only difference is that the chain has a single wrapper.
+type stackMessage struct {
+	err     error
+	msg     string
+	lazyMsg *lazyfmt.Message // If not nil, it is used instead of msg.
+	callers []uintptr
+}
+
+// inlineStackMessage stores the callers inline (see [errstack.InlineCallers]).
+type inlineStackMessage struct {
+	stackMessage
+	buf [errstack.InlineCallers]uintptr
+}
+
+func newStackMessage(err error, msg string, lazyMsg *lazyfmt.Message, skip int) *stackMessage {
+	var buf [errstack.InlineCallers]uintptr
+	n, shared := errstack.CaptureCallers(skip+1, &buf)
+	if shared != nil {
+		return &stackMessage{
+			err:     err,
+			msg:     msg,
+			lazyMsg: lazyMsg,
+			callers: shared,
+		}
+	}
+	s := &inlineStackMessage{
+		buf: buf,
+	}
+	s.stackMessage = stackMessage{
+		err:     err,
+		msg:     msg,
+		lazyMsg: lazyMsg,
+		callers: s.buf[:n:n],
+	}
+	return &s.stackMessage
+}
+
+func (err *stackMessage) Unwrap() error {
+	return err.err
+}
+
+func (err *stackMessage) Format(s fmt.State, verb rune) {
+	errverbose.Format(s, verb, err)
+}
+
+func (err *stackMessage) message() string {
+	if err.lazyMsg != nil {
+		return err.lazyMsg.String()
+	}
+	return err.msg
+}
+
+func (err *stackMessage) Error() string {
+	if err.err == nil {
+		return err.message()
+	}
+	return errappend.String(err)
+}
+
+func (err *stackMessage) ErrorAppend(b []byte) []byte {
+	msg := err.message()
+	if err.err == nil {
+		return append(b, msg...)
+	}
+	if msg != "" {
+		b = append(b, msg...)
+		b = append(b, ": "...)
+	}
+	return errappend.Append(b, err.err)
+}
+
+func (err *stackMessage) ErrorVerboseAppend(b []byte) []byte {
+	return err.ErrorVerboseAppendLimits(b, errverbose.DefaultLimits.Load())
+}
+
+func (err *stackMessage) ErrorVerboseAppendLimits(b []byte, limits errverbose.Limits) []byte {
+	return errstack.AppendVerbose(b, err.callers, limits)
+}
+
+func (err *stackMessage) StackFrames() []uintptr {
+	return err.callers
+}
+
+// formatMessage returns the formatted message, or a lazily formatted message if [errmsg.LazyFormat] is enabled.
+//
+// The arguments are not variadic, so go vet doesn't infer that its callers are wrappers of [fmt.Sprintf] (which doesn't support %w, unlike [Newf]).
+func formatMessage(format string, args []any) (string, *lazyfmt.Message) {
+	if errmsg.LazyFormat.Load() && lazyfmt.Supported(format, args) {
+		lazyMsg := new(lazyfmt.Message)
+		lazyMsg.Init(format, args)
+		return "", lazyMsg
+	}
+	return fmt.Sprintf(format, args...), nil
+}
+
+// hasStack is the same as the check done by [errstack.Ensure].
+func hasStack(err error) bool {
+	for err := range erriter.All(err) {
+		_, ok := err.(interface {
+			StackFrames() []uintptr
+		})
+		if ok {
+			return true
+		}
+	}
+	return false
+}